@@ -1,40 +1,35 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strconv"
-	"time"
 
-	"github.com/hyperledger/fabric/core/chaincode/shim"
-	pb "github.com/hyperledger/fabric/protos/peer"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/Common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
-// AnswerChaincode example simple Chaincode implementation
+// AnswerChaincode contract for submitting and evaluating answers
 type AnswerChaincode struct {
-}
-
-func toChaincodeArgs(args ...string) [][]byte {
-	bargs := make([][]byte, len(args))
-	for i, arg := range args {
-		bargs[i] = []byte(arg)
-	}
-	return bargs
+	contractapi.Contract
 }
 
 // ============================================================================================================================
 // Asset Definitions - The ledger will store answers with hash id and cid
 // ============================================================================================================================
 type Question struct {
-	QuestionHashID            string `json:"QuestionHashID"`
-	QuestionCID               string `json:"QuestionCID"`
-	QuestionerID              string `json:"QuestionerID"`
-	QuestionTech              string `json:"QuestionTech"`
-	RequiredEvaluatorThumbsUp int    `json:"RequiredEvaluatorThumbsUp"`
-	QuestionedOn              string `json:"QuestionedOn'`
+	QuestionHashID            string   `json:"QuestionHashID"`
+	QuestionCID               string   `json:"QuestionCID"`
+	QuestionerID              string   `json:"QuestionerID"`
+	QuestionTech              string   `json:"QuestionTech"`
+	RequiredEvaluatorThumbsUp int      `json:"RequiredEvaluatorThumbsUp"`
+	QuestionedOn              string   `json:"QuestionedOn"`
+	MinEvaluatorRepu          int      `json:"MinEvaluatorRepu,omitempty"`
+	PrerequisiteTech          string   `json:"PrerequisiteTech,omitempty"`
+	PrerequisiteRepu          int      `json:"PrerequisiteRepu,omitempty"`
+	PrerequisiteQuestions     []string `json:"PrerequisiteQuestions,omitempty"`
+	QuestionerEnrollmentID    string   `json:"QuestionerEnrollmentID,omitempty"`
+	RequiredOrganizations     int      `json:"RequiredOrganizations,omitempty"`
+	ClosesOn                  string   `json:"ClosesOn,omitempty"`
 }
 
 type Answer struct {
@@ -45,8 +40,63 @@ type Answer struct {
 	EvaluatedBy               []string `json:"EvaluatedBy"`
 	AttainedEvaluatorThumbsUp int      `json:"AttainedEvaluatorThumbsUp"`
 	AnsweredOn                string   `json:"AnsweredOn"`
+	Status                    string   `json:"Status"`
+	AcceptedOn                string   `json:"AcceptedOn,omitempty"`
+	// one per thumbs up, answers evaluated before evaluations were recorded only have EvaluatedBy
+	Evaluations []Evaluation `json:"Evaluations,omitempty"`
+	// the enrollment id of the student when the answer was submitted, its accounts cannot evaluate the answer
+	AnswererEnrollmentID string `json:"AnswererEnrollmentID,omitempty"`
+	// the evaluators assigned to the answer, only they can give a thumbs up when the answer was submitted while
+	// assignment was on
+	AssignedEvaluatorsOnly bool                  `json:"AssignedEvaluatorsOnly,omitempty"`
+	AssignedEvaluators     []EvaluatorAssignment `json:"AssignedEvaluators,omitempty"`
+	// a blind answer shows the AuthorHandle in place of AnsweredBy and AnswererEnrollmentID until its author is
+	// revealed once the answer is accepted or rejected, see Authorship.go
+	AuthorHandle     string `json:"AuthorHandle,omitempty"`
+	AuthorRevealedOn string `json:"AuthorRevealedOn,omitempty"`
+	// the time the student committed to an answer revealed after its question closed, see Commitment.go
+	CommittedOn string `json:"CommittedOn,omitempty"`
+	// the sha256 of the content, the answer hash id unless the answer is a copy of the answer of another student,
+	// flagged together with it, see Plagiarism.go
+	ContentHashID   string           `json:"ContentHashID,omitempty"`
+	PlagiarismFlags []PlagiarismFlag `json:"PlagiarismFlags,omitempty"`
+	// the test results of the graders, the passing ones count as AttainedGraderVotes towards acceptance, see Graders.go
+	GraderResults       []GraderResult `json:"GraderResults,omitempty"`
+	AttainedGraderVotes int            `json:"AttainedGraderVotes,omitempty"`
+	// set when a similarity attestation disputed the answer and when the review rejected it, see Similarity.go
+	DisputedOn string `json:"DisputedOn,omitempty"`
+	RejectedOn string `json:"RejectedOn,omitempty"`
+	// the appeal of the student against the outcome, decided by a panel of evaluators, see Appeals.go
+	Appeal *Appeal `json:"Appeal,omitempty"`
+}
+
+// Evaluation one thumbs up of an answer and the tech reputation that qualified the evaluator for it,
+// QualificationPath runs from the question's tech up to QualifiedTech, the tech the evaluator holds
+type Evaluation struct {
+	EvaluatorID       string   `json:"EvaluatorID"`
+	QualifiedTech     string   `json:"QualifiedTech"`
+	QualificationPath []string `json:"QualificationPath"`
+	QualifyingRepu    int      `json:"QualifyingRepu"`
+	MSPID             string   `json:"MSPID"`
+	EvaluatedOn       string   `json:"EvaluatedOn"`
+	// the accuracy of the evaluator when it gave the thumbs up, Calibrated once enough of its votes were scored for the
+	// accuracy to weigh the thumbs up, see Calibration.go
+	AccuracyPercent int  `json:"AccuracyPercent,omitempty"`
+	Calibrated      bool `json:"Calibrated,omitempty"`
 }
 
+// status of an answer, it is accepted once it attained the thumbs up required by its question, disputed while a
+// similarity attestation is reviewed, rejected when the review found it plagiarised and appealed while a panel decides
+// the appeal of its student
+// answers stored before the status was introduced have no status and are pending
+const (
+	AnswerStatusPending  = "PENDING"
+	AnswerStatusAccepted = "ACCEPTED"
+	AnswerStatusDisputed = "DISPUTED"
+	AnswerStatusRejected = "REJECTED"
+	AnswerStatusAppealed = "APPEALED"
+)
+
 type TechRepu struct {
 	UniqueTechName string `json:"UniqueTechName"`
 	AttainedRepu   int    `json:"AttainedRepo"`
@@ -54,539 +104,1037 @@ type TechRepu struct {
 }
 
 type Evaluator struct {
-	EvaluatorID        string     `json:"EvaluatorID"`
-	EvaluatorSecret    string     `json:"EvaluatorSecret"`
-	EvaluatedAnswers   []string   `json:"EvaluatedAnswers"` // only should container TechIDs so we can perform array ops on it with efficiency
-	EvaluatorTechRepus []TechRepu `json:"EvaluatorTechRepos"`
-	CreatedON          string     `json:"createdOn"`
+	EvaluatorID        string            `json:"EvaluatorID"`
+	EvaluatedAnswers   []string          `json:"EvaluatedAnswers"` // only should container TechIDs so we can perform array ops on it with efficiency
+	EvaluatorTechRepus []TechRepu        `json:"EvaluatorTechRepos"`
+	CreatedON          string            `json:"createdOn"`
+	LockedUntil        string            `json:"LockedUntil,omitempty"`
+	EnrollmentID       string            `json:"EnrollmentID,omitempty"`
+	DeclaredConflicts  []string          `json:"DeclaredConflicts,omitempty"`
+	Accuracy           EvaluatorAccuracy `json:"Accuracy"`
+}
+
+// EvaluatorAccuracy the votes of an evaluator scored so far, as kept by the Evaluator chaincode
+type EvaluatorAccuracy struct {
+	ScoredVotes     int `json:"ScoredVotes"`
+	CorrectVotes    int `json:"CorrectVotes"`
+	AccuracyPercent int `json:"AccuracyPercent"`
+}
+
+type Student struct {
+	StudentID         string     `json:"StudentID"`
+	StudentTechRepus  []TechRepu `json:"StudentTechRepos"`
+	AnsweredQuestions []string   `json:"AnsweredQuestions"`
+	CreatedON         string     `json:"createdOn"`
+	EnrollmentID      string     `json:"EnrollmentID,omitempty"`
+}
+
+// acceptedObjectType the accepted answer of a student to a question is indexed under a composite key of the student
+// and the question, so the prerequisites of a question are checked without a rich query
+const acceptedObjectType = "accepted"
+
+// AnswerQueryResult structure used for handling result of rich queries
+type AnswerQueryResult struct {
+	Key    string  `json:"Key"`
+	Record *Answer `json:"Record"`
 }
 
 // ============================================================================================================================
-// Main
+// Event Definitions - the events raised by the answer functions and their payloads, see Common/Events.go
 // ============================================================================================================================
-func main() {
-	err := shim.Start(new(AnswerChaincode))
-	if err != nil {
-		fmt.Printf("Error starting Simple chaincode - %s", err)
-	}
+
+// names of the events raised by the answer functions
+const (
+	EventAnswerSubmitted = "AnswerSubmitted"
+	EventAnswerEvaluated = "AnswerEvaluated"
+	EventAnswerAccepted  = "AnswerAccepted"
+)
+
+// AnswerSubmittedEvent payload of AnswerSubmitted, raised by SubmitAnswer
+type AnswerSubmittedEvent struct {
+	AnswerHashID string `json:"AnswerHashID"`
+	AnswerCID    string `json:"AnswerCID"`
+	AnsweredBy   string `json:"AnsweredBy"`
+	QuestionID   string `json:"QuestionID"`
+	AnsweredOn   string `json:"AnsweredOn"`
+	// blind answers are raised with their handle and an empty AnsweredBy
+	AuthorHandle string `json:"AuthorHandle,omitempty"`
+}
+
+// AnswerEvaluatedEvent payload of AnswerEvaluated, raised by ThumbsUpToAnswer for every thumbs up
+type AnswerEvaluatedEvent struct {
+	AnswerHashID              string `json:"AnswerHashID"`
+	QuestionID                string `json:"QuestionID"`
+	EvaluatorID               string `json:"EvaluatorID"`
+	AttainedEvaluatorThumbsUp int    `json:"AttainedEvaluatorThumbsUp"`
+	RequiredEvaluatorThumbsUp int    `json:"RequiredEvaluatorThumbsUp"`
+	AttainedOrganizations     int    `json:"AttainedOrganizations"`
+	RequiredOrganizations     int    `json:"RequiredOrganizations"`
+	EvaluatedOn               string `json:"EvaluatedOn"`
+}
+
+// AnswerAcceptedEvent payload of AnswerAccepted, raised by the ThumbsUpToAnswer that brings the answer
+// to the thumbs up required by its question, together with the AnswerEvaluated event of that thumbs up
+type AnswerAcceptedEvent struct {
+	AnswerHashID              string `json:"AnswerHashID"`
+	QuestionID                string `json:"QuestionID"`
+	AnsweredBy                string `json:"AnsweredBy"`
+	AttainedEvaluatorThumbsUp int    `json:"AttainedEvaluatorThumbsUp"`
+	AttainedGraderVotes       int    `json:"AttainedGraderVotes"`
+	AcceptedOn                string `json:"AcceptedOn"`
 }
 
 // ============================================================================================================================
-// Init - initialize the chaincode
+// Request Objects - the JSON request accepted by each function and its declared schema
 // ============================================================================================================================
-func (t *AnswerChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
-	fmt.Println("Answer Store Channel Is Starting Up")
-	funcName, args := stub.GetFunctionAndParameters()
-	var err error
-	txId := stub.GetTxID()
 
-	fmt.Println("  Init() is running")
-	fmt.Println("  Transaction ID: ", txId)
-	fmt.Println("  GetFunctionAndParameters() function: ", funcName)
-	fmt.Println("  GetFunctionAndParameters() args count: ", len(args))
-	fmt.Println("  GetFunctionAndParameters() args found: ", args)
+// InitLedgerRequest request object of InitLedger
+type InitLedgerRequest struct {
+	Key   string `json:"Key" validate:"maxlength=64"`
+	Value string `json:"Value" validate:"maxlength=256"`
+}
 
-	// expecting 1 arg for instantiate or upgrade
-	if len(args) == 2 {
-		fmt.Println("  GetFunctionAndParameters() : Number of arguments", len(args))
-	}
+// SubmitAnswerRequest request object of SubmitAnswer
+type SubmitAnswerRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	StudentsChaincode   string `json:"StudentsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" metadata:",optional" validate:"maxlength=64,format=chaincode"`
+	TechsChaincode      string `json:"TechsChaincode" metadata:",optional" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	AnswerCID           string `json:"AnswerCID" validate:"maxlength=128,format=cid"`
+	// left out while answers are evaluated blind, the student travels in the transient map then
+	AnsweredBy string `json:"AnsweredBy" metadata:",optional" validate:"maxlength=64,format=id"`
+	QuestionID string `json:"QuestionID" validate:"format=hash"`
+}
 
-	err = stub.PutState(args[0], []byte(args[1]))
-	if err != nil {
-		return shim.Error(err.Error()) //self-test fail
-	}
+// ThumbsUpToAnswerRequest request object of ThumbsUpToAnswer
+type ThumbsUpToAnswerRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" validate:"maxlength=64,format=chaincode"`
+	TechsChaincode      string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	EvaluatorID         string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+}
 
-	fmt.Println("Ready for action") //self-test pass
-	return shim.Success(nil)
+// ThumbsUpCountRequest request object of QueryAnswersByThumsUpCount
+type ThumbsUpCountRequest struct {
+	ThumbsUpCount int `json:"ThumbsUpCount" validate:"minimum=0,maximum=100"`
+}
+
+// AnswerIDRequest request object of the functions reading a single answer
+type AnswerIDRequest struct {
+	AnswerHashID string `json:"AnswerHashID" validate:"format=hash"`
 }
 
 // ============================================================================================================================
-// Invoke - Our entry point for Invocations
+// Transaction Context - helpers available to every answer transaction
 // ============================================================================================================================
-func (t *AnswerChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
-	function, args := stub.GetFunctionAndParameters()
-	fmt.Println(" ")
-	fmt.Println("starting invoke, for - " + function)
 
-	// Handle different functions
-	if function == "submitAnswer" { //create a new marble
-		return submitAnswer(stub, args)
-	} else if function == "thumbsUpToAnswer" { //update_answer
-		return thumbsUpToAnswer(stub, args)
-	} else if function == "queryAnswersByThumsUpCount" { //queryAnswersByStatus
-		return queryAnswersByThumsUpCount(stub, args)
-	} else if function == "queryAnswerByAnswerHashId" { //queryAnswerStatusByHash
-		return queryAnswerByAnswerHashId(stub, args)
+// TransactionContextInterface is the context handed to every AnswerChaincode function
+type TransactionContextInterface interface {
+	common.TransactionContextInterface
+	GetAnswer(answerHashID string) (*Answer, error)
+	PutAnswer(answer *Answer) error
+	HasAcceptedAnswer(studentID string, questionID string) (bool, error)
+	PutAcceptedAnswer(answer *Answer) error
+	GetAnswerCommitment(questionID string, studentID string) (*AnswerCommitment, error)
+	PutAnswerCommitment(commitment *AnswerCommitment) error
+	DelAnswerCommitment(questionID string, studentID string) error
+	PutContentCopy(contentHashID string, answerHashID string) error
+	GetContentCopies(contentHashID string) ([]string, error)
+	PutPlagiarismPair(pair *PlagiarismPair) error
+	GetGrader(graderID string) (*Grader, error)
+	PutGrader(grader *Grader) error
+	PutSimilarityAttestation(attestation *SimilarityAttestation) error
+	GetSimilarityAttestations(answerHashID string) ([]*SimilarityAttestation, error)
+	GetGoldStandard(answerHashID string) (*GoldStandard, error)
+	PutGoldStandard(gold *GoldStandard) error
+	GetTechRepuPolicy(techID string) (*TechRepuPolicy, error)
+	PutTechRepuPolicy(policy *TechRepuPolicy) error
+	GetAnswerAuthor(answerHashID string) (*AnswerAuthor, error)
+	PutAnswerAuthor(author *AnswerAuthor) error
+	DelAnswerAuthor(answerHashID string) error
+	GetBlindKey() (string, error)
+	PutBlindKey(key string) error
+	HasPendingAnswer(marker string) (bool, error)
+	PutPendingAnswer(marker string) error
+	DelPendingAnswer(marker string) error
+	GetConfig() (*Config, error)
+}
+
+// TransactionContext implementation of TransactionContextInterface
+type TransactionContext struct {
+	common.TransactionContext
+}
+
+// GetAnswer reads an answer from the world state, nil if it does not exist
+func (ctx *TransactionContext) GetAnswer(answerHashID string) (*Answer, error) {
+	answerAsBytes, err := ctx.GetStub().GetState(answerHashID)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding answer for - %s", answerHashID)
+	}
+	if answerAsBytes == nil {
+		return nil, nil
 	}
 
-	// error out
-	fmt.Println("Received unknown invoke function name - " + function)
-	return shim.Error("Received unknown invoke function name - '" + function + "'")
+	ans, err := JSONtoAns(answerAsBytes)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to convert JSONtoAns for %s", answerHashID)
+	}
+	return &ans, nil
 }
 
-// ============================================================================================================================
-// Query - legacy function
-// ============================================================================================================================
-func (t *AnswerChaincode) Query(stub shim.ChaincodeStubInterface) pb.Response {
-	return shim.Error("Unknown supported call - Query()")
+// PutAnswer writes an answer to the world state keyed by its hash id, without the author while it is hidden
+func (ctx *TransactionContext) PutAnswer(answer *Answer) error {
+	stored := *answer
+	if isAuthorHidden(answer) {
+		stored.AnsweredBy = ""
+		stored.AnswererEnrollmentID = ""
+	}
+	buff, err := AnsToJSON(stored)
+	if err != nil {
+		return common.InternalError(err, "unable to convert answer object to json")
+	}
+	err = ctx.GetStub().PutState(answer.AnswerHashID, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write answer - %s", answer.AnswerHashID)
+	}
+	return nil
+}
+
+// HasAcceptedAnswer whether the student has an accepted answer to the question
+func (ctx *TransactionContext) HasAcceptedAnswer(studentID string, questionID string) (bool, error) {
+	acceptedKey, err := ctx.GetStub().CreateCompositeKey(acceptedObjectType, []string{studentID, questionID})
+	if err != nil {
+		return false, common.InternalError(err, "unable to create the accepted answer key for %s", questionID)
+	}
+	answerHashID, err := ctx.GetStub().GetState(acceptedKey)
+	if err != nil {
+		return false, common.InternalError(err, "error in finding the accepted answer of %s to %s", studentID, questionID)
+	}
+	return answerHashID != nil, nil
+}
+
+// PutAcceptedAnswer indexes the accepted answer against its student and question
+func (ctx *TransactionContext) PutAcceptedAnswer(answer *Answer) error {
+	acceptedKey, err := ctx.GetStub().CreateCompositeKey(acceptedObjectType, []string{answer.AnsweredBy, answer.QuestionID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the accepted answer key for %s", answer.QuestionID)
+	}
+	err = ctx.GetStub().PutState(acceptedKey, []byte(answer.AnswerHashID))
+	if err != nil {
+		return common.InternalError(err, "unable to index the accepted answer - %s", answer.AnswerHashID)
+	}
+	return nil
 }
 
 // ============================================================================================================================
-// Get Answer - get a answer asset from ledger
+// Main
 // ============================================================================================================================
-func getAnswer(stub shim.ChaincodeStubInterface, id string) (Answer, error) {
-	ans := Answer{}
-	answerAsBytes, err := stub.GetState(id) //getState retreives a key/value from the ledger
-	if err == nil {                         //this seems to always succeed, even if key didn't exist
-		return ans, errors.New("Failed to find marble - " + id)
+func main() {
+	chaincode, err := newAnswerChaincode()
+	if err != nil {
+		fmt.Printf("Error creating Answer chaincode - %s", err)
+		return
 	}
 
-	if answerAsBytes == nil { //test if marble is actually here or just nil
-		return ans, errors.New("Answer does not exist - " + id)
+	err = chaincode.Start()
+	if err != nil {
+		fmt.Printf("Error starting Answer chaincode - %s", err)
 	}
+}
+
+// newAnswerChaincode the contract api chaincode of the Answer contract, fails when the contract's metadata is rejected
+func newAnswerChaincode() (*contractapi.ContractChaincode, error) {
+	answerChaincode := new(AnswerChaincode)
+	answerChaincode.TransactionContextHandler = new(TransactionContext)
+	answerChaincode.UnknownTransaction = common.UnknownTransaction
+	answerChaincode.BeforeTransaction = common.BeforeTransaction(answerChaincode)
+	answerChaincode.AfterTransaction = common.AfterTransaction
 
-	err = json.Unmarshal([]byte(answerAsBytes), &ans)
+	chaincode, err := contractapi.NewChaincode(answerChaincode)
 	if err != nil {
-		fmt.Println("Unmarshal failed : ", err)
-		return ans, errors.New("unable to unmarshall")
+		return nil, err
 	}
+	chaincode.Info.Title = "Answers"
+	chaincode.Info.Version = "2.0.0"
+	return chaincode, nil
+}
 
-	fmt.Println(ans)
-	return ans, nil
+// ============================================================================================================================
+// InitLedger - initialize the chaincode
+// ============================================================================================================================
+func (t *AnswerChaincode) InitLedger(ctx TransactionContextInterface, req InitLedgerRequest) error {
+	fmt.Println("Answer Store Channel Is Starting Up")
+	fmt.Println("  InitLedger() is running")
+	fmt.Println("  Transaction ID: ", ctx.GetStub().GetTxID())
+
+	err := ctx.GetStub().PutState(req.Key, []byte(req.Value))
+	if err != nil {
+		return common.InternalError(err, "self-test write failed") //self-test fail
+	}
+
+	fmt.Println("Ready for action") //self-test pass
+	return nil
 }
 
-func submitAnswer(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
+// SubmitAnswer stores a new answer to an existing question and records it against the student
+func (t *AnswerChaincode) SubmitAnswer(ctx TransactionContextInterface, req SubmitAnswerRequest) (*common.AuthenticationResult, error) {
 	fmt.Println("starting submitAnswer")
 
-	if len(args) != 6 {
-		fmt.Println("initAnswer(): Incorrect number of arguments. Expecting 6 ")
-		return shim.Error("intAnswer(): Incorrect number of arguments. Expecting 6 ")
+	result, err := submitAnswer(ctx, "SubmitAnswer", req, nil)
+	if err != nil || !result.Authenticated {
+		return result, err
 	}
 
-	//input sanitation
-	err1 := sanitize_arguments(args)
-	if err1 != nil {
-		return shim.Error("Cannot sanitize arguments")
-	}
-	questionsChaincode := args[0]
-	studentsChaincode := args[1]
+	fmt.Println("- end submitAnswer")
+	return result, nil
+}
 
-	answerHashID := args[2]
-	// answerCID := args[3]
-	answeredBy := args[4]
-	questionID := args[5]
-	fmt.Println("========================= recieved args ==========================")
-	fmt.Println(args)
+// CommitAnswer commits the student to an answer to a question with a close time without giving away its CID
+func (t *AnswerChaincode) CommitAnswer(ctx TransactionContextInterface, req CommitAnswerRequest) (*common.AuthenticationResult, error) {
+	return commitAnswer(ctx, req)
+}
 
-	// ==================================== check the valid question ===========================================
-	channelId := ""
-	chainCodeToCall := questionsChaincode //"questions2"
-	functionName := "getQuestionById"
-	queryKey := questionID
+// RevealAnswer submits the answer the student committed to once the question closed
+func (t *AnswerChaincode) RevealAnswer(ctx TransactionContextInterface, req RevealAnswerRequest) (*common.AuthenticationResult, error) {
+	return revealAnswer(ctx, req)
+}
 
-	queryArgs := toChaincodeArgs(functionName, queryKey)
-	response := stub.InvokeChaincode(chainCodeToCall, queryArgs, channelId)
-	if response.Status != shim.OK {
-		errStr := fmt.Sprintf("Failed to query chaincode. Got error: %s", err.Error())
-		fmt.Printf(errStr)
-		return shim.Error("error in finding evaluator for - " + questionID)
-	}
-	questionBytes := response.Payload
+// QueryAnswersByThumsUpCount rich query for the answers that attained the given thumbs up count
+func (t *AnswerChaincode) QueryAnswersByThumsUpCount(ctx TransactionContextInterface, req ThumbsUpCountRequest) ([]*AnswerQueryResult, error) {
+	queryString := fmt.Sprintf("{\"selector\":{\"AttainedEvaluatorThumbsUp\":%d}}", req.ThumbsUpCount)
 
-	str := fmt.Sprintf("%s", questionBytes)
-	fmt.Println("string is " + str)
+	return getQueryResultForQueryString(ctx, queryString)
+}
 
-	questionData, err := JSONtoQues(questionBytes)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in unmarshelling - " + questionID)
-		return shim.Error("Error in unmarshelling - " + questionID)
-	}
-	fmt.Println("captured questions data ")
-	fmt.Println(questionData)
-	// ============================================================================================
+// QueryAnswerByAnswerHashId rich query for the answer by its hash id
+func (t *AnswerChaincode) QueryAnswerByAnswerHashId(ctx TransactionContextInterface, req AnswerIDRequest) ([]*AnswerQueryResult, error) {
+	queryString := fmt.Sprintf("{\"selector\":{\"AnswerHashDigest\":\"%s\"}}", req.AnswerHashID)
+
+	return getQueryResultForQueryString(ctx, queryString)
+}
+
+// ThumbsUpToAnswer for thumbsup first validate the registered evaluator by evaluator secret from the evaluator chaincode
+// then allow the evaluator to do a thumsup against an answer hash id
+// iff the evaluator has the tech reputation the question demands in its tech, or inherits it from a parent tech
+func (t *AnswerChaincode) ThumbsUpToAnswer(ctx TransactionContextInterface, req ThumbsUpToAnswerRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting thumbsUpToAnswer")
+
+	answerHashID := req.AnswerHashID
+	evaluatorID := req.EvaluatorID
 
-	//check if answer id already exists
-	answerAsBytes, err := stub.GetState(answerHashID)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		return shim.Error("error in finding asnswer for - " + answerHashID)
+	// ================================== Query the question ledger ================================================
+	dat, err := getAnswerLedgerState(ctx, answerHashID)
+	if err != nil {
+		return nil, err
+	}
+	dat, err = withAuthor(ctx, dat)
+	if err != nil {
+		return nil, err
 	}
-	if answerAsBytes != nil {
-		fmt.Println("This answer already exists - " + answerHashID)
-		return shim.Error("This answer already exists - " + answerHashID) //all stop a marble by this id exists
+	if dat.Status == AnswerStatusDisputed || dat.Status == AnswerStatusRejected || dat.Status == AnswerStatusAppealed {
+		return nil, common.NewError(common.ErrInvalidState, "the answer %s is %s", answerHashID, dat.Status)
 	}
 
-	answerObject, err := CreateAnswerObject(args[2:])
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, dat.QuestionID)
 	if err != nil {
-		errorStr := "submitAnswer() : Failed Cannot create object buffer for write : " + args[0]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return nil, err
 	}
+	answerTech := questionData.QuestionTech
 
-	fmt.Println(answerObject)
-	buff, err := AnsToJSON(answerObject)
+	//  first check that whether this evaluator id and the secret are right from the evaluator chaincode
+	//  then check the tech repu of the evaluator
+	//  grab evaluator tech repu array by evaluator id and grab evaluator's tech repu as per the tech
+	//===============================================================================================
+	evaluatorsData, result, err := authenticateEvaluator(ctx, req.EvaluatorsChaincode, evaluatorID)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+
+	clientID, err := common.GetClientID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := getTechAncestors(ctx, req.TechsChaincode, answerTech)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ctx.GetConfig()
 	if err != nil {
-		return shim.Error("unable to convert answer object to json")
+		return nil, err
+	}
+	evaluation, err := checkEvaluatorEligible(evaluatorsData, clientID, questionData, dat, ancestors, config.ParentRepuPercent)
+	if err != nil {
+		return nil, err
 	}
 
-	// also update the student ledger for this answer to the question in the student's aswers array
+	evaluatedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	err = markAssignmentEvaluated(dat, evaluatorID, evaluatedOn)
+	if err != nil {
+		return nil, err
+	}
 
-	f := "updateAnsweredQuestions"
-	channelID := ""
-	chainCodeToCall = studentsChaincode //"students3"
+	dat.EvaluatedBy = append(dat.EvaluatedBy, evaluatorID)
+	evaluation.EvaluatedOn = evaluatedOn
+	evaluation.MSPID, err = common.GetClientMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config.AccuracyTracking {
+		evaluation.AccuracyPercent = evaluatorsData.Accuracy.AccuracyPercent
+		evaluation.Calibrated = evaluatorsData.Accuracy.ScoredVotes >= config.CalibrationVotes
+	}
+	dat.Evaluations = append(dat.Evaluations, evaluation)
+	dat.AttainedEvaluatorThumbsUp = dat.AttainedEvaluatorThumbsUp + 1
 
-	invokeArgs := toChaincodeArgs(f, answeredBy, questionID)
+	accepted, err := acceptIfAttained(ctx, dat, questionData, evaluatedOn)
+	if err != nil {
+		return nil, err
+	}
 
-	response = stub.InvokeChaincode(chainCodeToCall, invokeArgs, channelID)
-	if response.Status != shim.OK {
-		errStr := fmt.Sprintf("Failed to invoke chaincode. Got error: %s", string(response.Payload))
-		fmt.Printf(errStr)
-		return shim.Error(errStr)
+	// update the evaluated answers of the evaluator, its thumbs up is scored and rewarded at once when the outcome is known
+	evaluatedAnswer := map[string]interface{}{"EvaluatorID": evaluatorID, "AnswerHashID": answerHashID}
+	outcome, gold, err := knownOutcome(ctx, dat, config)
+	if err != nil {
+		return nil, err
 	}
-	//======================================================================================================
+	if outcome != "" {
+		evaluatedAnswer["Outcome"] = outcome
+		evaluatedAnswer["Gold"] = gold
+	}
+	reward := 0
+	if accepted {
+		policy, err := findRepuPolicy(ctx, questionData.QuestionTech)
+		if err != nil {
+			return nil, err
+		}
+		reward = policy.RewardRepu
+	}
+	if reward != 0 {
+		evaluatedAnswer["TechName"] = evaluation.QualifiedTech
+		evaluatedAnswer["RepuDelta"] = reward
+	}
+	changesAsBytes, err := ctx.CallChaincode(req.EvaluatorsChaincode, "UpdateTheEvaluatedAnswers", evaluatedAnswer)
+	if err != nil {
+		return nil, common.LiftDependencyError(err, []string{common.ErrInvalidState}, "evaluator %s cannot evaluate the answer %s", evaluatorID, answerHashID)
+	}
+	err = emitEvaluatorChanges(ctx, changesAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	if accepted {
+		err = settleVotes(ctx, "ThumbsUpToAnswer", req.EvaluatorsChaincode, dat, questionData, reward, evaluatorID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	//==========================================================
 
-	err = stub.PutState(answerHashID, buff) //store marble with id as key
+	err = ctx.PutAnswer(dat)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, err
+	}
+	if accepted {
+		err = ctx.PutAcceptedAnswer(dat)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx.EmitEvent(EventAnswerEvaluated, AnswerEvaluatedEvent{answerHashID, dat.QuestionID, evaluatorID, dat.AttainedEvaluatorThumbsUp, questionData.RequiredEvaluatorThumbsUp,
+		countOrganizations(dat.Evaluations), getRequiredOrganizations(questionData), evaluatedOn})
+	if accepted {
+		ctx.EmitEvent(EventAnswerAccepted, AnswerAcceptedEvent{answerHashID, dat.QuestionID, dat.AnsweredBy, dat.AttainedEvaluatorThumbsUp, dat.AttainedGraderVotes, evaluatedOn})
 	}
 
-	fmt.Println("- end submitAnswer")
-	return shim.Success(nil)
+	fmt.Println("- end thumbsUpToAnswer")
+	return result, nil
 }
 
-func queryAnswersByThumsUpCount(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+// RegisterGrader registers the identity invoking it as a grader, pending until an admin approves it
+func (t *AnswerChaincode) RegisterGrader(ctx TransactionContextInterface, req GraderIDRequest) error {
+	return registerGrader(ctx, req)
+}
 
-	if len(args) < 1 {
-		return shim.Error("Incorrect number of arguments. Expecting 1")
-	}
+// ApproveGrader admin only, lets a pending grader submit results
+func (t *AnswerChaincode) ApproveGrader(ctx TransactionContextInterface, req GraderIDRequest) error {
+	return changeGraderStatus(ctx, "ApproveGrader", req, GraderStatusActive)
+}
 
-	thumbsUpCount := args[0]
+// RevokeGrader admin only, stops a grader from submitting results for good
+func (t *AnswerChaincode) RevokeGrader(ctx TransactionContextInterface, req GraderIDRequest) error {
+	return changeGraderStatus(ctx, "RevokeGrader", req, GraderStatusRevoked)
+}
 
-	queryString := fmt.Sprintf("{\"selector\":{\"AttainedEvaluatorThumbsUp\":\"%s\"}}", thumbsUpCount)
+// GetGraderById returns the grader stored against the id
+func (t *AnswerChaincode) GetGraderById(ctx TransactionContextInterface, req GraderIDRequest) (*Grader, error) {
+	return getGraderById(ctx, req)
+}
 
-	queryResults, err := getQueryResultForQueryString(stub, queryString)
-	if err != nil {
-		return shim.Error(err.Error())
-	}
-	return shim.Success(queryResults)
+// SubmitGraderResult records the test results of a grader for an answer as a weighted vote
+func (t *AnswerChaincode) SubmitGraderResult(ctx TransactionContextInterface, req SubmitGraderResultRequest) error {
+	return submitGraderResult(ctx, req)
 }
 
-func queryAnswerByAnswerHashId(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+// AttestSimilarity plagiarism checker only, records the similarity of two answers and disputes them above the threshold
+func (t *AnswerChaincode) AttestSimilarity(ctx TransactionContextInterface, req AttestSimilarityRequest) error {
+	return attestSimilarity(ctx, req)
+}
 
-	//   0
-	// "bob"
-	if len(args) != 1 {
-		return shim.Error("Incorrect number of arguments. Expecting 1")
-	}
+// GetSimilarityAttestations lists the similarity attestations linked to an answer
+func (t *AnswerChaincode) GetSimilarityAttestations(ctx TransactionContextInterface, req AnswerIDRequest) ([]*SimilarityAttestation, error) {
+	return getSimilarityAttestations(ctx, req)
+}
 
-	answerHashID := args[0]
-	queryString := fmt.Sprintf("{\"selector\":{\"AnswerHashID\":\"%s\"}}", answerHashID)
+// ResolveDispute admin only, clears a disputed answer or rejects it
+func (t *AnswerChaincode) ResolveDispute(ctx TransactionContextInterface, req ResolveDisputeRequest) error {
+	return resolveDispute(ctx, req)
+}
 
-	queryResults, err := getQueryResultForQueryString(stub, queryString)
-	if err != nil {
-		return shim.Error(err.Error())
-	}
-	return shim.Success(queryResults)
+// AppealAnswer lets the student appeal a rejected or stalled answer to a panel of evaluators of higher reputation
+func (t *AnswerChaincode) AppealAnswer(ctx TransactionContextInterface, req AppealAnswerRequest) (*common.AuthenticationResult, error) {
+	return appealAnswer(ctx, req)
+}
+
+// SetBlindEvaluationKey admin only, sets the key blind answers are kept private with, taken from the transient map
+func (t *AnswerChaincode) SetBlindEvaluationKey(ctx TransactionContextInterface) error {
+	return setBlindEvaluationKey(ctx)
+}
+
+// VoteOnAppeal records the vote of a panel evaluator on an appeal and decides it once the panel has a majority
+func (t *AnswerChaincode) VoteOnAppeal(ctx TransactionContextInterface, req VoteOnAppealRequest) (*common.AuthenticationResult, error) {
+	return voteOnAppeal(ctx, req)
 }
 
-func getQueryResultForQueryString(stub shim.ChaincodeStubInterface, queryString string) ([]byte, error) {
+// SeedGoldAnswer admin only, stores a gold-standard answer with a known outcome to score the evaluators against
+func (t *AnswerChaincode) SeedGoldAnswer(ctx TransactionContextInterface, req SeedGoldAnswerRequest) error {
+	return seedGoldAnswer(ctx, req)
+}
+
+// SetTechRepuPolicy admin only, sets the reputation the evaluators of answers to questions of a tech gain and lose
+func (t *AnswerChaincode) SetTechRepuPolicy(ctx TransactionContextInterface, req SetTechRepuPolicyRequest) error {
+	return setTechRepuPolicy(ctx, req)
+}
+
+// GetTechRepuPolicy returns the reward and penalty in use for a tech
+func (t *AnswerChaincode) GetTechRepuPolicy(ctx TransactionContextInterface, req TechRepuPolicyRequest) (*TechRepuPolicy, error) {
+	return getTechRepuPolicy(ctx, req)
+}
+
+// GetFlaggedAnswerPairs lists the byte-identical answers of different students flagged as plagiarised
+func (t *AnswerChaincode) GetFlaggedAnswerPairs(ctx TransactionContextInterface) ([]*PlagiarismPair, error) {
+	return getFlaggedAnswerPairs(ctx, "")
+}
+
+// GetFlaggedAnswerPairsForQuestion lists the flagged pairs with an answer to the question
+func (t *AnswerChaincode) GetFlaggedAnswerPairsForQuestion(ctx TransactionContextInterface, req FlaggedAnswerPairsRequest) ([]*PlagiarismPair, error) {
+	return getFlaggedAnswerPairs(ctx, req.QuestionID)
+}
+
+// ReassignEvaluators replaces the evaluators assigned to the answer whose assignment is past due
+func (t *AnswerChaincode) ReassignEvaluators(ctx TransactionContextInterface, req ReassignEvaluatorsRequest) error {
+	return reassignEvaluators(ctx, req)
+}
+
+// GetPendingEvaluationsForEvaluator the answers waiting for a thumbs up the evaluator can give, oldest first and paged
+func (t *AnswerChaincode) GetPendingEvaluationsForEvaluator(ctx TransactionContextInterface, req PendingEvaluationsRequest) (*PendingEvaluationsPage, error) {
+	return getPendingEvaluationsForEvaluator(ctx, req)
+}
+
+// SetConfig admin only, replaces the evaluator qualification, assignment, blind evaluation, grader, similarity, appeal,
+// accuracy and reputation settings
+func (t *AnswerChaincode) SetConfig(ctx TransactionContextInterface, config Config) error {
+	return common.SetConfig(ctx, &config, defaultConfig)
+}
+
+// GetConfig returns the settings in use, the defaults until an admin set them
+func (t *AnswerChaincode) GetConfig(ctx TransactionContextInterface) (*Config, error) {
+	return ctx.GetConfig()
+}
+
+// GetRequestSchemas lists the declared request schema of every function
+func (t *AnswerChaincode) GetRequestSchemas(ctx TransactionContextInterface) []common.RequestSchema {
+	return common.RequestSchemas(t)
+}
+
+// ====================================================== Private Library ====================================================
+
+func getQueryResultForQueryString(ctx TransactionContextInterface, queryString string) ([]*AnswerQueryResult, error) {
 
 	fmt.Printf("- getQueryResultForQueryString queryString:\n%s\n", queryString)
 
-	resultsIterator, err := stub.GetQueryResult(queryString)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
 	if err != nil {
-		return nil, err
+		return nil, common.InternalError(err, "rich query failed")
 	}
 	defer resultsIterator.Close()
 
-	// buffer is a JSON array containing QueryRecords
-	var buffer bytes.Buffer
-	buffer.WriteString("[")
-
-	bArrayMemberAlreadyWritten := false
+	results := []*AnswerQueryResult{}
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
 		if err != nil {
-			return nil, err
-		}
-		// Add a comma before array members, suppress it for the first array member
-		if bArrayMemberAlreadyWritten == true {
-			buffer.WriteString(",")
+			return nil, common.InternalError(err, "unable to iterate the query result")
 		}
-		buffer.WriteString("{\"Key\":")
-		buffer.WriteString("\"")
-		buffer.WriteString(queryResponse.Key)
-		buffer.WriteString("\"")
 
-		buffer.WriteString(", \"Record\":")
-		// Record is a JSON object, so we write as-is
-		buffer.WriteString(string(queryResponse.Value))
-		buffer.WriteString("}")
-		bArrayMemberAlreadyWritten = true
+		ans, err := JSONtoAns(queryResponse.Value)
+		if err != nil {
+			return nil, common.InternalError(err, "unable to unmarshall answer - %s", queryResponse.Key)
+		}
+		results = append(results, &AnswerQueryResult{Key: queryResponse.Key, Record: &ans})
 	}
-	buffer.WriteString("]")
 
-	fmt.Printf("- getQueryResultForQueryString queryResult:\n%s\n", buffer.String())
+	fmt.Printf("- getQueryResultForQueryString found %d records\n", len(results))
 
-	return buffer.Bytes(), nil
+	return results, nil
 }
 
-func queryOtherChaincodeByKeyOnly(stub shim.ChaincodeStubInterface, args []string) (pb.Response, error) {
-
-	fmt.Println("starting thumbsUpToAnswer")
-	// var arr []byte
-
-	if len(args) != 4 {
-		return shim.Error(""), errors.New("Incorrect number of arguments. Expecting 4")
-	}
+func getAnswerLedgerState(ctx TransactionContextInterface, answerHashID string) (*Answer, error) {
+	fmt.Println("starting getAnswerLedgerState")
 
-	//input sanitation
-	err := sanitize_arguments(args)
+	dat, err := ctx.GetAnswer(answerHashID)
 	if err != nil {
-		return shim.Error(""), errors.New("error in sanitization")
+		return nil, err
 	}
-	channelID := args[0]
-	chainCodeToCall := args[1]
-	functionName := args[2]
-	queryKey := args[3]
-
-	queryArgs := toChaincodeArgs(functionName, queryKey)
-	response := stub.InvokeChaincode(chainCodeToCall, queryArgs, channelID)
-	if response.Status != shim.OK {
-		errStr := fmt.Sprintf("Failed to query chaincode. Got error: %s", err.Error())
-		fmt.Printf(errStr)
-		return shim.Error(""), errors.New(errStr)
+	if dat == nil {
+		return nil, common.NewError(common.ErrNotFound, "Nil value for %s", answerHashID)
 	}
-	bytesResponse := response.Payload
 
-	return shim.Success(bytesResponse), nil
+	return dat, nil
 }
 
-func getAnswerLedgerState(stub shim.ChaincodeStubInterface, args []string) (Answer, error) {
-	// action can be performed
-	var myAnswer Answer
-	fmt.Println("starting getAnswerLedgerState")
+// submitAnswer stores the answer of the request once the student is authenticated, the answer of a question with a
+// close time only when it is revealed with the commitment the student made before the question closed
+func submitAnswer(ctx TransactionContextInterface, function string, req SubmitAnswerRequest, commitment *AnswerCommitment) (*common.AuthenticationResult, error) {
+	fieldErrors := common.CheckCIDBinding("AnswerCID", req.AnswerCID, "AnswerHashID", req.AnswerHashID)
+	if len(fieldErrors) > 0 {
+		return nil, common.ValidationError(function, fieldErrors)
+	}
+	answerHashID := req.AnswerHashID
+	questionID := req.QuestionID
+	answeredBy, err := getAnsweredBy(ctx, function, req.AnsweredBy)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(args) != 1 {
-		return myAnswer, errors.New("Incorrect number of arguments. Expecting 1")
+	// ============================ authenticate the student against the student chaincode =====================
+	studentData, result, err := authenticateStudent(ctx, req.StudentsChaincode, answeredBy)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	err = assertNotAnswered(ctx, studentData, questionID)
+	if err != nil {
+		return nil, err
 	}
 
-	//input sanitation
-	err := sanitize_arguments(args)
+	// ==================================== check the valid question ===========================================
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, questionID)
 	if err != nil {
-		return myAnswer, errors.New("problem in sanitization")
+		return nil, err
 	}
-	answerHashID := args[0]
+	fmt.Println("captured questions data ")
+	fmt.Println(questionData)
 
-	answerDataBytes, err := stub.GetState(answerHashID)
-	str := fmt.Sprintf("%s", answerDataBytes)
-	fmt.Println("string is " + str)
+	err = assertPrerequisitesMet(ctx, studentData, questionData)
+	if err != nil {
+		return nil, err
+	}
+	// ============================================================================================
 
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Answer  for - " + answerHashID)
-		return myAnswer, errors.New("Error in finding Answer  for - " + answerHashID)
+	//check if answer id already exists, an answer byte-identical to the answer of another student is stored as a copy
+	// under a hash id of its own
+	matches, err := findContentMatches(ctx, answerHashID)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		if match.AnsweredBy == answeredBy {
+			return nil, common.NewError(common.ErrAlreadyExists, "This answer already exists - %s", match.AnswerHashID)
+		}
+	}
+	if len(matches) > 0 {
+		answerHashID = copyHashID(ctx.GetStub().GetTxID(), req.AnswerHashID)
 	}
 
-	if answerDataBytes == nil {
-		jsonResp := "{\"Error\":\"Nil value for " + answerHashID + "\"}"
-		return myAnswer, errors.New(jsonResp)
+	answeredOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
 	}
-	dat, err := JSONtoAns(answerDataBytes)
+	err = assertAnswerWindow(questionData, commitment, answeredOn)
 	if err != nil {
-		return myAnswer, errors.New("unable to convert JSONtoAns for" + answerHashID)
+		return nil, err
 	}
 
-	return dat, nil
-}
-
-// for thumbsup first validate the registered evaluator by evaluator secret from the evaluator chaincode
-// then allow the evaluator to do a thumsup against an answer hash id
-// iff the evaluator has a tech reputation more than 1000
-func thumbsUpToAnswer(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
-	// var jsonResp string
-
-	if len(args) != 5 {
-		return shim.Error("Incorrect number of arguments. Expecting 5")
+	answerObject := CreateAnswerObject(answerHashID, req.AnswerCID, answeredBy, questionID, answeredOn, studentData.EnrollmentID)
+	answerObject.ContentHashID = req.AnswerHashID
+	if commitment != nil {
+		answerObject.CommittedOn = commitment.CommittedOn
 	}
+	fmt.Println(answerObject)
 
-	//input sanitation
-	err = sanitize_arguments(args)
+	config, err := ctx.GetConfig()
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, err
+	}
+	var assignedEvent *EvaluatorsAssignedEvent
+	if config.AssignedEvaluators > 0 {
+		var fieldErrors []common.FieldError
+		if req.EvaluatorsChaincode == "" {
+			fieldErrors = append(fieldErrors, common.FieldError{Field: "EvaluatorsChaincode", Message: "is required while evaluators are assigned to answers"})
+		}
+		if req.TechsChaincode == "" {
+			fieldErrors = append(fieldErrors, common.FieldError{Field: "TechsChaincode", Message: "is required while evaluators are assigned to answers"})
+		}
+		if len(fieldErrors) > 0 {
+			return nil, common.ValidationError(function, fieldErrors)
+		}
+		answerObject.AssignedEvaluatorsOnly = true
+		assignedEvent, err = assignEvaluators(ctx, req.EvaluatorsChaincode, req.TechsChaincode, questionData, &answerObject, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	submittedEvent := AnswerSubmittedEvent{answerHashID, req.AnswerCID, answeredBy, questionID, answeredOn, ""}
+	if config.BlindEvaluation {
+		// the student ledger records the answer once revealAuthor reveals its author
+		err = hideAuthor(ctx, &answerObject, req.StudentsChaincode)
+		if err != nil {
+			return nil, err
+		}
+		submittedEvent.AnsweredBy = ""
+		submittedEvent.AuthorHandle = answerObject.AuthorHandle
+	} else {
+		// also update the student ledger for this answer to the question in the student's aswers array
+		_, err = ctx.CallChaincode(req.StudentsChaincode, "UpdateAnsweredQuestions", map[string]string{"StudentID": answeredBy, "QuestionID": questionID})
+		if err != nil {
+			return nil, common.LiftDependencyError(err, []string{common.ErrNotFound, common.ErrInvalidState}, "student %s cannot answer the question %s", answeredBy, questionID)
+		}
 	}
-	questionsChaincode := args[0]
-	evaluatorsChaincode := args[1]
+	//======================================================================================================
 
-	answerHashID := args[2]
-	evaluatorID := args[3]
-	rawEvaluatorSecret := args[4]
+	var flaggedEvent *AnswerFlaggedEvent
+	if len(matches) > 0 {
+		flaggedEvent, err = flagCopies(ctx, &answerObject, matches)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = ctx.PutAnswer(&answerObject)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitEvent(EventAnswerSubmitted, submittedEvent)
+	if assignedEvent != nil {
+		ctx.EmitEvent(EventEvaluatorsAssigned, assignedEvent)
+	}
+	if flaggedEvent != nil {
+		ctx.EmitEvent(EventAnswerFlagged, flaggedEvent)
+	}
+	return result, nil
+}
 
-	// ================================== Query the question ledger ================================================
-	var ledgerQueryList []string
-	ledgerQueryList = append(ledgerQueryList, answerHashID)
+// authenticateStudent the student checked against its secret by the AuthenticateStudent of the Student chaincode,
+// LOCKED while it is locked, the secret hashes never leave the Student chaincode and the StudentSecret reaches it in
+// the transient map of the proposal, which the called chaincode shares. A wrong secret is the rejected result, which the
+// function returns as its own so the failure the Student chaincode counted is committed
+func authenticateStudent(ctx TransactionContextInterface, studentsChaincode string, studentID string) (Student, *common.AuthenticationResult, error) {
+	result, err := authenticate(ctx, studentsChaincode, "AuthenticateStudent", "STUDENT", studentID, map[string]string{"StudentID": studentID})
+	if err != nil {
+		return Student{}, nil, common.LiftDependencyError(err, authenticationErrors, "unable to authenticate the student %s", studentID)
+	}
+	if !result.Authenticated {
+		return Student{}, result, nil
+	}
+	studentData, err := getStudentFromChaincode(ctx, studentsChaincode, studentID)
+	return studentData, result, err
+}
 
-	dat, err := getAnswerLedgerState(stub, ledgerQueryList)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Answer  for - " + answerHashID)
-		return shim.Error("error in finding answer for - " + answerHashID)
+// authenticateEvaluator the evaluator checked against its EvaluatorSecret by the AuthenticateEvaluator of the Evaluator
+// chaincode, LOCKED while it is locked and the rejected result for a wrong secret, see authenticateStudent
+func authenticateEvaluator(ctx TransactionContextInterface, evaluatorsChaincode string, evaluatorID string) (Evaluator, *common.AuthenticationResult, error) {
+	result, err := authenticate(ctx, evaluatorsChaincode, "AuthenticateEvaluator", "EVALUATOR", evaluatorID, map[string]string{"EvaluatorID": evaluatorID})
+	if err != nil {
+		return Evaluator{}, nil, common.LiftDependencyError(err, authenticationErrors, "unable to authenticate the evaluator %s", evaluatorID)
+	}
+	if !result.Authenticated {
+		return Evaluator{}, result, nil
 	}
+	evaluatorsData, err := getEvaluatorFromChaincode(ctx, evaluatorsChaincode, evaluatorID)
+	return evaluatorsData, result, err
+}
 
-	channelId := ""
-	chainCodeToCall := questionsChaincode //"questions2"
-	functionName := "getQuestionById"
-	queryKey := dat.QuestionID
+// the codes of a failed authentication passed on as they are, a missing secret included
+var authenticationErrors = []string{common.ErrNotFound, common.ErrLocked, common.ErrValidationFailed}
 
-	queryArgs := toChaincodeArgs(functionName, queryKey)
-	response := stub.InvokeChaincode(chainCodeToCall, queryArgs, channelId)
-	if response.Status != shim.OK {
-		errStr := fmt.Sprintf("Failed to query chaincode. Got error: %s", err.Error())
-		fmt.Printf(errStr)
-		return shim.Error("error in finding evaluator for - " + evaluatorID)
+// authenticate calls the authentication audit transaction of a Student or Evaluator chaincode, the events of a called
+// chaincode never reach the client so a failure raises AuthenticationFailed, and AccountLocked, again from this one
+func authenticate(ctx TransactionContextInterface, chaincodeName string, functionName string, subjectType string, subjectID string, request map[string]string) (*common.AuthenticationResult, error) {
+	resultBytes, err := ctx.CallChaincode(chaincodeName, functionName, request)
+	if err != nil {
+		return nil, err
 	}
-	questionBytes := response.Payload
+	result := common.AuthenticationResult{}
+	err = json.Unmarshal(resultBytes, &result)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall the %s result", functionName)
+	}
+	if !result.Authenticated {
+		failedOn, err := ctx.GetTxTime()
+		if err != nil {
+			return nil, err
+		}
+		ctx.EmitEvent(common.EventAuthenticationFailed, common.AuthenticationFailedEvent{SubjectType: subjectType, SubjectID: subjectID, FailedAuthAttempts: result.FailedAuthAttempts, FailedOn: failedOn})
+		if result.LockedUntil != "" {
+			ctx.EmitEvent(common.EventAccountLocked, common.AccountLockedEvent{SubjectType: subjectType, SubjectID: subjectID, LockedUntil: result.LockedUntil})
+		}
+	}
+	return &result, nil
+}
 
-	str := fmt.Sprintf("%s", questionBytes)
-	fmt.Println("string is " + str)
+// getQuestionFromChaincode fetches a question through the Question chaincode
+func getQuestionFromChaincode(ctx TransactionContextInterface, questionsChaincode string, questionID string) (Question, error) {
+	questionBytes, err := ctx.CallChaincode(questionsChaincode, "GetQuestionById", map[string]string{"QuestionHashID": questionID})
+	if err != nil {
+		return Question{}, common.LiftDependencyError(err, []string{common.ErrNotFound}, "error in finding question for - %s", questionID)
+	}
 
 	questionData, err := JSONtoQues(questionBytes)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in unmarshelling - " + evaluatorID)
-		return shim.Error("Error in unmarshelling - " + evaluatorID)
+	if err != nil {
+		return questionData, common.InternalError(err, "Error in unmarshelling - %s", questionID)
 	}
-	answerTech := questionData.QuestionTech
-
-	//  first check that whether this evaluator id and the secret are right from the evaluator chaincode
-	//  then check the tech repu of the evaluator
-	//  grab evaluator tech repu array by evaluator id and grab evaluator's tech repu as per the tech
-	//===============================================================================================
-
-	channelId = ""
-	chainCodeToCall = evaluatorsChaincode //"evaluators9"
-	functionName = "getEvaluatorById"
-	queryKey = evaluatorID
-
-	// evaluatorsData := evaluatorsBytes
-	fmt.Println("=======================================================")
-	fmt.Println(" =========================== " + queryKey)
+	return questionData, nil
+}
 
-	queryArgs = toChaincodeArgs(functionName, queryKey)
-	fmt.Println(chainCodeToCall)
-	fmt.Println(queryArgs)
-	fmt.Println(channelId)
-	fmt.Println("=======================================================")
+// getStudentFromChaincode fetches a student through the Student chaincode
+func getStudentFromChaincode(ctx TransactionContextInterface, studentsChaincode string, studentID string) (Student, error) {
+	studentBytes, err := ctx.CallChaincode(studentsChaincode, "GetStudentById", map[string]string{"StudentID": studentID})
+	if err != nil {
+		return Student{}, common.LiftDependencyError(err, []string{common.ErrNotFound}, "error in finding student for - %s", studentID)
+	}
 
-	response = stub.InvokeChaincode(chainCodeToCall, queryArgs, channelId)
-	if response.Status != shim.OK {
-		errStr := fmt.Sprintf("Failed to query chaincode. Got error: %s", err.Error())
-		fmt.Printf(errStr)
-		return shim.Error("error in finding evaluator for - " + evaluatorID)
+	studentData := Student{}
+	err = json.Unmarshal(studentBytes, &studentData)
+	if err != nil {
+		return studentData, common.InternalError(err, "Error in unmarshelling - %s", studentID)
 	}
-	evaluatorsBytes := response.Payload
-	fmt.Println("=======================================================")
+	return studentData, nil
+}
 
-	str = fmt.Sprintf("%s", evaluatorsBytes)
-	fmt.Println("string is " + str)
+// getEvaluatorFromChaincode fetches an evaluator through the Evaluator chaincode
+func getEvaluatorFromChaincode(ctx TransactionContextInterface, evaluatorsChaincode string, evaluatorID string) (Evaluator, error) {
+	evaluatorsBytes, err := ctx.CallChaincode(evaluatorsChaincode, "GetEvaluatorById", map[string]string{"EvaluatorID": evaluatorID})
+	if err != nil {
+		return Evaluator{}, common.LiftDependencyError(err, []string{common.ErrNotFound}, "error in finding evaluator for - %s", evaluatorID)
+	}
 
 	evaluatorsData, err := JSONtoEval(evaluatorsBytes)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in unmarshelling - " + evaluatorID)
-		return shim.Error("Error in unmarshelling - " + evaluatorID)
+	if err != nil {
+		return evaluatorsData, common.InternalError(err, "Error in unmarshelling - %s", evaluatorID)
 	}
-	// answerTech = evaluatorsData.QuestionTech
+	return evaluatorsData, nil
+}
 
-	// now grab and test the evaluator secret if it is right
-	hashedEvalSecret := evaluatorsData.EvaluatorSecret
+// getTechAncestors the ancestors of the question's tech from its parent up to the root, none for a free text tech
+// of a question submitted before the tech registry
+func getTechAncestors(ctx TransactionContextInterface, techsChaincode string, techName string) ([]string, error) {
+	tech, err := common.ResolveTech(ctx, techsChaincode, techName)
+	if chaincodeError, ok := err.(*common.ChaincodeError); ok && chaincodeError.Code == common.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tech.Ancestors, nil
+}
 
-	isSuccess := CheckPasswordHash(rawEvaluatorSecret, hashedEvalSecret)
-	if !isSuccess {
-		errStr := fmt.Sprintf("not authorized to perform this action. ")
-		fmt.Printf(errStr)
-		return shim.Error(errStr)
+// qualifyEvaluator the evaluator's best reputation for the question's tech, either held in the tech itself or
+// inherited from an ancestor at parentRepuPercent of the reputation per level, false if the evaluator holds none of them
+func qualifyEvaluator(evaluator Evaluator, questionTech string, ancestors []string, parentRepuPercent int) (Evaluation, bool) {
+	repus := map[string]int{}
+	for _, techRepuData := range evaluator.EvaluatorTechRepus {
+		repus[techRepuData.UniqueTechName] = techRepuData.AttainedRepu
 	}
-	techRepuArray := evaluatorsData.EvaluatorTechRepus
 
+	evaluation := Evaluation{EvaluatorID: evaluator.EvaluatorID}
 	flag := false
-	attainedTechRepu := 0
-	for _, techRepuData := range techRepuArray {
-		if answerTech == techRepuData.UniqueTechName {
+	path := []string{questionTech}
+	if repu, found := repus[questionTech]; found {
+		evaluation.QualifiedTech = questionTech
+		evaluation.QualificationPath = path
+		evaluation.QualifyingRepu = repu
+		flag = true
+	}
+	if parentRepuPercent == 0 {
+		return evaluation, flag
+	}
+
+	for level, ancestor := range ancestors {
+		path = append(path, ancestor)
+		repu, found := repus[ancestor]
+		if !found {
+			continue
+		}
+		for i := 0; i <= level; i++ {
+			repu = repu * parentRepuPercent / 100
+		}
+		if !flag || repu > evaluation.QualifyingRepu {
+			evaluation.QualifiedTech = ancestor
+			evaluation.QualificationPath = append([]string{}, path...)
+			evaluation.QualifyingRepu = repu
 			flag = true
-			attainedTechRepu = techRepuData.AttainedRepu
-			break
 		}
 	}
-	if flag && attainedTechRepu > 1000 {
-
-		// First just update the evaluated answers of the evaluator
-		f := "updateTheEvaluatedAnswers"
-		channelID := ""
-		chainCodeToCall = evaluatorsChaincode //"evaluators9"
-
-		invokeArgs := toChaincodeArgs(f, evaluatorID, answerHashID)
+	return evaluation, flag
+}
 
-		response := stub.InvokeChaincode(chainCodeToCall, invokeArgs, channelID)
-		if response.Status != shim.OK {
-			errStr := fmt.Sprintf("Failed to invoke chaincode. Got error: %s", string(response.Payload))
-			fmt.Printf(errStr)
-			return shim.Error(errStr)
+// assertPrerequisitesMet fails with FORBIDDEN unless the student has the reputation the question requires in its
+// prerequisite tech and an accepted answer to each of its prerequisite questions
+func assertPrerequisitesMet(ctx TransactionContextInterface, student Student, question Question) error {
+	if question.PrerequisiteTech != "" {
+		attainedRepu := 0
+		for _, techRepuData := range student.StudentTechRepus {
+			if techRepuData.UniqueTechName == question.PrerequisiteTech {
+				attainedRepu = techRepuData.AttainedRepu
+				break
+			}
 		}
-		//==========================================================
-		evalyBy := dat.EvaluatedBy
-		evalyBy = append(evalyBy, evaluatorID)
-
-		thumbsUp := dat.AttainedEvaluatorThumbsUp + 1
-
-		updatedAnswer := Answer{dat.AnswerHashID, dat.AnswerCID, dat.AnsweredBy, dat.QuestionID, evalyBy, thumbsUp, dat.AnsweredOn}
+		if attainedRepu < question.PrerequisiteRepu {
+			return common.NewError(common.ErrForbidden, "student %s needs a reputation of %d in %s to answer the question %s", student.StudentID, question.PrerequisiteRepu, question.PrerequisiteTech, question.QuestionHashID)
+		}
+	}
 
-		buff, err := AnsToJSON(updatedAnswer)
+	for _, prerequisiteID := range question.PrerequisiteQuestions {
+		accepted, err := ctx.HasAcceptedAnswer(student.StudentID, prerequisiteID)
 		if err != nil {
-			errorStr := "updateDispatchOrder() : Failed Cannot create object buffer for write : " + args[1]
-			fmt.Println(errorStr)
-			return shim.Error(errorStr)
+			return err
 		}
+		if !accepted {
+			return common.NewError(common.ErrForbidden, "student %s needs an accepted answer to the question %s to answer the question %s", student.StudentID, prerequisiteID, question.QuestionHashID)
+		}
+	}
+	return nil
+}
 
-		err = stub.PutState(answerHashID, buff) //store marble with id as key
-		if err != nil {
-			return shim.Error(err.Error())
+// assertNoConflict fails with FORBIDDEN when the evaluator has to recuse from the answer: the evaluator asked the
+// question or wrote the answer, shares an enrollment identity with the questioner or the student, or declared a conflict
+// with either of them, clientID is the identity acting for the evaluator, empty when no one is
+func assertNoConflict(evaluator Evaluator, clientID string, question Question, answer *Answer) error {
+	if evaluator.EvaluatorID == question.QuestionerID || evaluator.EvaluatorID == answer.AnsweredBy {
+		return common.NewError(common.ErrForbidden, "evaluator %s cannot evaluate an answer to its own question or its own answer", evaluator.EvaluatorID)
+	}
+	if stringInSlice(question.QuestionerID, evaluator.DeclaredConflicts) || stringInSlice(answer.AnsweredBy, evaluator.DeclaredConflicts) {
+		return common.NewError(common.ErrForbidden, "evaluator %s declared a conflict of interest with the answer %s", evaluator.EvaluatorID, answer.AnswerHashID)
+	}
+
+	// records written before enrollment ids were recorded have none and are only checked by id
+	for _, evaluatorEnrollment := range []string{evaluator.EnrollmentID, clientID} {
+		if evaluatorEnrollment == "" {
+			continue
+		}
+		if evaluatorEnrollment == question.QuestionerEnrollmentID || evaluatorEnrollment == answer.AnswererEnrollmentID {
+			return common.NewError(common.ErrForbidden, "evaluator %s is linked to the questioner or the student of the answer %s by its enrollment identity", evaluator.EvaluatorID, answer.AnswerHashID)
 		}
+	}
+	return nil
+}
+
+// checkEvaluatorEligible the evaluation the evaluator qualifies for, FORBIDDEN when the evaluator has to recuse from
+// the answer or lacks the reputation the question demands in its tech
+func checkEvaluatorEligible(evaluator Evaluator, clientID string, question Question, answer *Answer, ancestors []string, parentRepuPercent int) (Evaluation, error) {
+	err := assertNoConflict(evaluator, clientID, question, answer)
+	if err != nil {
+		return Evaluation{}, err
+	}
 
-		fmt.Println("- end thumbsUpToAnswer")
-		return shim.Success(nil)
+	minEvaluatorRepu := getMinEvaluatorRepu(question)
+	evaluation, flag := qualifyEvaluator(evaluator, question.QuestionTech, ancestors, parentRepuPercent)
+	if !flag || evaluation.QualifyingRepu < minEvaluatorRepu {
+		return Evaluation{}, common.NewError(common.ErrForbidden, "either you dont have required tech repu or the tech repu is less than %d", minEvaluatorRepu)
+	}
+	return evaluation, nil
+}
 
-	} else {
-		errStr := fmt.Sprintf("either you dont have required tech repu or the tech repu is less than 1000. ")
-		fmt.Printf(errStr)
-		return shim.Error(errStr)
+// getMinEvaluatorRepu the reputation the question requires of its evaluators, questions submitted before it was
+// introduced require common.DefaultMinEvaluatorRepu
+func getMinEvaluatorRepu(question Question) int {
+	if question.MinEvaluatorRepu == 0 {
+		return common.DefaultMinEvaluatorRepu
 	}
+	return question.MinEvaluatorRepu
 }
 
-// ====================================================== Private Library ====================================================
+// acceptIfAttained accepts the answer once the thumbs up of its evaluators, weighed by their accuracy while AccuracyWeighting
+// is on, together with the weighted votes of its graders attain the RequiredEvaluatorThumbsUp of its question and the
+// evaluators came from its RequiredOrganizations, the answer must have been read through withAuthor and the caller
+// writes it
+func acceptIfAttained(ctx TransactionContextInterface, answer *Answer, question Question, now string) (bool, error) {
+	if !isOpenForEvaluation(answer) {
+		return false, nil
+	}
+	// a gold-standard answer only scores its evaluators
+	gold, err := ctx.GetGoldStandard(answer.AnswerHashID)
+	if err != nil {
+		return false, err
+	}
+	if gold != nil {
+		return false, nil
+	}
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return false, err
+	}
+	thumbsUp := answer.AttainedEvaluatorThumbsUp * 100
+	if config.AccuracyWeighting {
+		thumbsUp = weighThumbsUp(answer)
+	}
+	if thumbsUp+answer.AttainedGraderVotes*100 < question.RequiredEvaluatorThumbsUp*100 ||
+		countOrganizations(answer.Evaluations) < getRequiredOrganizations(question) {
+		return false, nil
+	}
 
-func sanitize_arguments(strs []string) error {
-	for i, val := range strs {
-		if len(val) <= 0 {
-			return errors.New("Argument " + strconv.Itoa(i) + " must be a non-empty string")
-		}
-		// if len(val) > 32 {
-		// 	return errors.New("Argument " + strconv.Itoa(i) + " must be <= 32 characters")
-		// }
+	answer.Status = AnswerStatusAccepted
+	answer.AcceptedOn = now
+	err = revealAuthor(ctx, answer, now)
+	if err != nil {
+		return false, err
 	}
-	return nil
+	return true, nil
 }
 
-// CreateAssetObject creates an asset
-func CreateAnswerObject(args []string) (Answer, error) {
-	var myAnswer Answer
+// getRequiredOrganizations the organizations the evaluators of an answer to the question have to come from, questions
+// submitted before it was introduced have none and only need one
+func getRequiredOrganizations(question Question) int {
+	if question.RequiredOrganizations == 0 {
+		return 1
+	}
+	return question.RequiredOrganizations
+}
 
-	strArr := []string{}
-	// Check there are 10 Arguments provided as per the the struct
-	if len(args) != 4 {
-		fmt.Println("CreateAnswerObject(): Incorrect number of arguments. Expecting 4")
-		return myAnswer, errors.New("CreateAnswerObject(): Incorrect number of arguments. Expecting 4")
+// countOrganizations the distinct organizations the evaluations came from
+func countOrganizations(evaluations []Evaluation) int {
+	organizations := map[string]bool{}
+	for _, evaluation := range evaluations {
+		if evaluation.MSPID != "" {
+			organizations[evaluation.MSPID] = true
+		}
 	}
+	return len(organizations)
+}
 
-	myAnswer = Answer{args[0], args[1], args[2], args[3], strArr, 0, time.Now().Format("20060102150405")}
-	return myAnswer, nil
+// CreateAnswerObject creates an answer asset
+func CreateAnswerObject(answerHashID string, answerCID string, answeredBy string, questionID string, answeredOn string, enrollmentID string) Answer {
+	return Answer{
+		AnswerHashID:         answerHashID,
+		AnswerCID:            answerCID,
+		AnsweredBy:           answeredBy,
+		QuestionID:           questionID,
+		EvaluatedBy:          []string{},
+		AnsweredOn:           answeredOn,
+		Status:               AnswerStatusPending,
+		AnswererEnrollmentID: enrollmentID,
+	}
 }
 
 func AnsToJSON(ans Answer) ([]byte, error) {
@@ -664,8 +1212,3 @@ func stringInSlice(a string, list []string) bool {
 	}
 	return false
 }
-
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Common"
+)
+
+func TestQualifyEvaluator(t *testing.T) {
+	ancestors := []string{"go", "programming"}
+	tests := []struct {
+		name              string
+		repus             map[string]int
+		parentRepuPercent int
+		qualified         bool
+		tech              string
+		path              []string
+		repu              int
+	}{
+		{name: "question tech", repus: map[string]int{"gin": 40}, parentRepuPercent: 50, qualified: true, tech: "gin", path: []string{"gin"}, repu: 40},
+		{name: "parent tech", repus: map[string]int{"go": 100}, parentRepuPercent: 50, qualified: true, tech: "go", path: []string{"gin", "go"}, repu: 50},
+		{name: "grandparent tech", repus: map[string]int{"programming": 100}, parentRepuPercent: 50, qualified: true, tech: "programming", path: []string{"gin", "go", "programming"}, repu: 25},
+		{name: "higher inherited reputation wins", repus: map[string]int{"gin": 40, "go": 100}, parentRepuPercent: 50, qualified: true, tech: "go", path: []string{"gin", "go"}, repu: 50},
+		{name: "higher own reputation wins", repus: map[string]int{"gin": 60, "go": 100}, parentRepuPercent: 50, qualified: true, tech: "gin", path: []string{"gin"}, repu: 60},
+		{name: "no inheritance", repus: map[string]int{"go": 100}, parentRepuPercent: 0, qualified: false},
+		{name: "unrelated tech", repus: map[string]int{"rust": 100}, parentRepuPercent: 50, qualified: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			evaluator := Evaluator{EvaluatorID: "e1"}
+			for techName, repu := range test.repus {
+				evaluator.EvaluatorTechRepus = append(evaluator.EvaluatorTechRepus, TechRepu{UniqueTechName: techName, AttainedRepu: repu})
+			}
+			evaluation, qualified := qualifyEvaluator(evaluator, "gin", ancestors, test.parentRepuPercent)
+			if qualified != test.qualified {
+				t.Fatalf("qualifyEvaluator(%v) qualified = %t, want %t", test.repus, qualified, test.qualified)
+			}
+			if !qualified {
+				return
+			}
+			if evaluation.QualifiedTech != test.tech || evaluation.QualifyingRepu != test.repu || !reflect.DeepEqual(evaluation.QualificationPath, test.path) {
+				t.Errorf("qualifyEvaluator(%v) = %s %d %v, want %s %d %v", test.repus, evaluation.QualifiedTech, evaluation.QualifyingRepu,
+					evaluation.QualificationPath, test.tech, test.repu, test.path)
+			}
+		})
+	}
+}
+
+func TestAssertNoConflict(t *testing.T) {
+	question := Question{QuestionHashID: "q1", QuestionerID: "s1", QuestionerEnrollmentID: "user1"}
+	answer := &Answer{AnswerHashID: "a1", AnsweredBy: "s2", AnswererEnrollmentID: "user2"}
+	tests := []struct {
+		name      string
+		evaluator Evaluator
+		clientID  string
+		forbidden bool
+	}{
+		{name: "unrelated evaluator", evaluator: Evaluator{EvaluatorID: "e1", EnrollmentID: "user3"}, clientID: "user3"},
+		{name: "record without enrollment", evaluator: Evaluator{EvaluatorID: "e1"}, clientID: ""},
+		{name: "asked the question", evaluator: Evaluator{EvaluatorID: "s1"}, forbidden: true},
+		{name: "wrote the answer", evaluator: Evaluator{EvaluatorID: "s2"}, forbidden: true},
+		{name: "declared a conflict with the questioner", evaluator: Evaluator{EvaluatorID: "e1", DeclaredConflicts: []string{"s1"}}, forbidden: true},
+		{name: "declared a conflict with the student", evaluator: Evaluator{EvaluatorID: "e1", DeclaredConflicts: []string{"s2"}}, forbidden: true},
+		{name: "enrolled as the questioner", evaluator: Evaluator{EvaluatorID: "e1", EnrollmentID: "user1"}, forbidden: true},
+		{name: "acting as the student", evaluator: Evaluator{EvaluatorID: "e1", EnrollmentID: "user3"}, clientID: "user2", forbidden: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := assertNoConflict(test.evaluator, test.clientID, question, answer)
+			if !test.forbidden {
+				if err != nil {
+					t.Fatalf("assertNoConflict(%+v) failed - %s", test.evaluator, err)
+				}
+				return
+			}
+			chaincodeError, ok := err.(*common.ChaincodeError)
+			if !ok || chaincodeError.Code != common.ErrForbidden {
+				t.Errorf("assertNoConflict(%+v) = %v, want a %s envelope", test.evaluator, err, common.ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestNewAnswerChaincode(t *testing.T) {
+	_, err := newAnswerChaincode()
+	if err != nil {
+		t.Errorf("newAnswerChaincode failed - %s", err)
+	}
+	// panics on a constraint that is not a number
+	common.RequestSchemas(new(AnswerChaincode))
+}
+
+func TestPendingEvaluationsPageSize(t *testing.T) {
+	for _, field := range common.SchemaOf("GetPendingEvaluationsForEvaluator", reflect.TypeOf(PendingEvaluationsRequest{})).Fields {
+		if field.Name == "PageSize" && field.Maximum != MaxPendingPageSize {
+			t.Errorf("PageSize maximum = %d, want %d", field.Maximum, MaxPendingPageSize)
+		}
+	}
+}
+
+func TestAssignmentSeed(t *testing.T) {
+	answer := &Answer{AnswerHashID: "a1"}
+	tests := []struct {
+		name        string
+		assignments []EvaluatorAssignment
+		expected    string
+	}{
+		{name: "first assignment", expected: "a1:0:"},
+		{name: "reassignment", assignments: []EvaluatorAssignment{{EvaluatorID: "e1", DueOn: "20240102120000", Status: AssignmentStatusExpired}}, expected: "a1:1:20240102120000"},
+		{name: "later reassignment", assignments: []EvaluatorAssignment{
+			{EvaluatorID: "e1", DueOn: "20240102120000", Status: AssignmentStatusExpired},
+			{EvaluatorID: "e2", DueOn: "20240103120000", Status: AssignmentStatusExpired},
+		}, expected: "a1:2:20240103120000"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			answer.AssignedEvaluators = test.assignments
+			seed := assignmentSeed(answer)
+			if seed != test.expected {
+				t.Errorf("assignmentSeed(%+v) = %q, want %q", test.assignments, seed, test.expected)
+			}
+		})
+	}
+}
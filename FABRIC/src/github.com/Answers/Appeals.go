@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Appeals - the student of a rejected answer, or of an answer left pending for AppealAfterMinutes, appeals it once with
+// the CID of its reasons, a panel of evaluators of higher reputation that did not evaluate the answer is picked and its
+// majority overturns the outcome by accepting the answer or upholds it by rejecting the answer, the evaluators that gave
+// the answer a thumbs up gain the RewardRepu of its question's tech when the panel accepts it and lose its PenaltyRepu
+// when the panel rejects it, see RepuPolicy.go
+// ============================================================================================================================
+
+// status of an appeal, it is open until a majority of its panel, or all of it, voted
+const (
+	AppealStatusOpen       = "OPEN"
+	AppealStatusOverturned = "OVERTURNED"
+	AppealStatusUpheld     = "UPHELD"
+)
+
+// names of the events raised by the appeal functions
+const (
+	EventAnswerAppealed = "AnswerAppealed"
+	EventAppealVoted    = "AppealVoted"
+	EventAppealDecided  = "AppealDecided"
+)
+
+// Appeal the appeal of the student against the outcome of its answer, PreviousStatus is the status it was appealed in
+type Appeal struct {
+	ReasonCID         string       `json:"ReasonCID"`
+	PreviousStatus    string       `json:"PreviousStatus"`
+	PanelEvaluatorIDs []string     `json:"PanelEvaluatorIDs"`
+	Votes             []AppealVote `json:"Votes"`
+	Status            string       `json:"Status"`
+	AppealedOn        string       `json:"AppealedOn"`
+	DecidedOn         string       `json:"DecidedOn,omitempty"`
+}
+
+// AppealVote the vote of a panel evaluator, Accept when the evaluator holds the answer should be accepted
+type AppealVote struct {
+	EvaluatorID string `json:"EvaluatorID"`
+	Accept      bool   `json:"Accept"`
+	MSPID       string `json:"MSPID"`
+	VotedOn     string `json:"VotedOn"`
+}
+
+// AnswerAppealedEvent payload of AnswerAppealed, raised by AppealAnswer
+type AnswerAppealedEvent struct {
+	AnswerHashID      string   `json:"AnswerHashID"`
+	QuestionID        string   `json:"QuestionID"`
+	ReasonCID         string   `json:"ReasonCID"`
+	PreviousStatus    string   `json:"PreviousStatus"`
+	PanelEvaluatorIDs []string `json:"PanelEvaluatorIDs"`
+	AppealedOn        string   `json:"AppealedOn"`
+}
+
+// AppealVotedEvent payload of AppealVoted, raised by VoteOnAppeal for every vote
+type AppealVotedEvent struct {
+	AnswerHashID string `json:"AnswerHashID"`
+	EvaluatorID  string `json:"EvaluatorID"`
+	Accept       bool   `json:"Accept"`
+	AcceptVotes  int    `json:"AcceptVotes"`
+	RejectVotes  int    `json:"RejectVotes"`
+	VotedOn      string `json:"VotedOn"`
+}
+
+// AppealDecidedEvent payload of AppealDecided, raised by the VoteOnAppeal that decides the appeal, together with the
+// AnswerAccepted event when the panel accepted the answer
+type AppealDecidedEvent struct {
+	AnswerHashID   string `json:"AnswerHashID"`
+	Outcome        string `json:"Outcome"`
+	Status         string `json:"Status"`
+	AcceptVotes    int    `json:"AcceptVotes"`
+	RejectVotes    int    `json:"RejectVotes"`
+	RepuAdjustment int    `json:"RepuAdjustment"`
+	DecidedOn      string `json:"DecidedOn"`
+}
+
+// AppealAnswerRequest request object of AppealAnswer
+type AppealAnswerRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	StudentsChaincode   string `json:"StudentsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" validate:"maxlength=64,format=chaincode"`
+	TechsChaincode      string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	AnsweredBy          string `json:"AnsweredBy" validate:"maxlength=64,format=id"`
+	ReasonCID           string `json:"ReasonCID" validate:"maxlength=128,format=cid"`
+}
+
+// VoteOnAppealRequest request object of VoteOnAppeal
+type VoteOnAppealRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	EvaluatorID         string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	Accept              bool   `json:"Accept"`
+}
+
+// appealAnswer opens the appeal of the student against the outcome of its answer and picks its panel, an answer is
+// appealed once
+func appealAnswer(ctx TransactionContextInterface, req AppealAnswerRequest) (*common.AuthenticationResult, error) {
+	_, result, err := authenticateStudent(ctx, req.StudentsChaincode, req.AnsweredBy)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	dat, err := getAnswerLedgerState(ctx, req.AnswerHashID)
+	if err != nil {
+		return nil, err
+	}
+	dat, err = withAuthor(ctx, dat)
+	if err != nil {
+		return nil, err
+	}
+	if dat.AnsweredBy != req.AnsweredBy {
+		return nil, common.NewError(common.ErrForbidden, "student %s can only appeal its own answers", req.AnsweredBy)
+	}
+	if dat.Appeal != nil {
+		return nil, common.NewError(common.ErrAlreadyExists, "the answer %s was already appealed on %s", req.AnswerHashID, dat.Appeal.AppealedOn)
+	}
+	gold, err := ctx.GetGoldStandard(req.AnswerHashID)
+	if err != nil {
+		return nil, err
+	}
+	if gold != nil {
+		return nil, common.NewError(common.ErrInvalidState, "the answer %s cannot be appealed", req.AnswerHashID)
+	}
+
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	appealedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	stalledOn, err := common.AddMinutes(dat.AnsweredOn, config.AppealAfterMinutes)
+	if err != nil {
+		return nil, err
+	}
+	if dat.Status != AnswerStatusRejected && !(isOpenForEvaluation(dat) && appealedOn >= stalledOn) {
+		return nil, common.NewError(common.ErrInvalidState, "the answer %s can only be appealed once rejected or while pending after %s", req.AnswerHashID, stalledOn)
+	}
+
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, dat.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	minRepu := getMinEvaluatorRepu(questionData) * config.AppealPanelRepuPercent / 100
+	candidates, err := getEligibleEvaluators(ctx, req.EvaluatorsChaincode, req.TechsChaincode, questionData, dat, config, appealedOn, minRepu)
+	if err != nil {
+		return nil, err
+	}
+	var unvoted []string
+	for _, evaluatorID := range candidates {
+		if !stringInSlice(evaluatorID, dat.EvaluatedBy) {
+			unvoted = append(unvoted, evaluatorID)
+		}
+	}
+	panel := selectEvaluators(ctx.GetStub().GetTxID()+dat.AnswerHashID+":appeal", unvoted, config.AppealPanelSize)
+	if len(panel) == 0 {
+		return nil, common.NewError(common.ErrInvalidState, "no evaluator with a reputation of %d that did not evaluate the answer %s is eligible for its panel", minRepu, req.AnswerHashID)
+	}
+
+	dat.Appeal = &Appeal{req.ReasonCID, dat.Status, panel, []AppealVote{}, AppealStatusOpen, appealedOn, ""}
+	dat.Status = AnswerStatusAppealed
+	err = ctx.PutAnswer(dat)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitEvent(EventAnswerAppealed, AnswerAppealedEvent{req.AnswerHashID, dat.QuestionID, req.ReasonCID, dat.Appeal.PreviousStatus, panel, appealedOn})
+
+	fmt.Printf("- end appealAnswer %s with a panel of %d\n", req.AnswerHashID, len(panel))
+	return result, nil
+}
+
+// voteOnAppeal records the vote of a panel evaluator, the majority of the panel decides the appeal and a panel split
+// evenly once all of it voted upholds the outcome
+func voteOnAppeal(ctx TransactionContextInterface, req VoteOnAppealRequest) (*common.AuthenticationResult, error) {
+	dat, err := getAnswerLedgerState(ctx, req.AnswerHashID)
+	if err != nil {
+		return nil, err
+	}
+	if dat.Status != AnswerStatusAppealed || dat.Appeal == nil {
+		return nil, common.NewError(common.ErrInvalidState, "the answer %s is not appealed", req.AnswerHashID)
+	}
+	if !stringInSlice(req.EvaluatorID, dat.Appeal.PanelEvaluatorIDs) {
+		return nil, common.NewError(common.ErrForbidden, "evaluator %s is not on the panel of the answer %s", req.EvaluatorID, req.AnswerHashID)
+	}
+	for _, vote := range dat.Appeal.Votes {
+		if vote.EvaluatorID == req.EvaluatorID {
+			return nil, common.NewError(common.ErrAlreadyExists, "evaluator %s already voted on the appeal of the answer %s", req.EvaluatorID, req.AnswerHashID)
+		}
+	}
+	dat, err = withAuthor(ctx, dat)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluatorsData, result, err := authenticateEvaluator(ctx, req.EvaluatorsChaincode, req.EvaluatorID)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	clientID, err := common.GetClientID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, dat.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	err = assertNoConflict(evaluatorsData, clientID, questionData, dat)
+	if err != nil {
+		return nil, err
+	}
+
+	votedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := common.GetClientMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dat.Appeal.Votes = append(dat.Appeal.Votes, AppealVote{req.EvaluatorID, req.Accept, mspID, votedOn})
+	acceptVotes, rejectVotes, status := tallyAppeal(dat.Appeal)
+
+	accepted := false
+	var decided *AppealDecidedEvent
+	if status != AppealStatusOpen {
+		policy, err := findRepuPolicy(ctx, questionData.QuestionTech)
+		if err != nil {
+			return nil, err
+		}
+		dat.Appeal.DecidedOn = votedOn
+		adjustment := policy.RewardRepu
+		dat.Appeal.Status = status
+		if status == AppealStatusOverturned {
+			dat.Status = AnswerStatusAccepted
+			dat.AcceptedOn = votedOn
+			err = revealAuthor(ctx, dat, votedOn)
+			accepted = true
+		} else {
+			err = rejectAnswer(ctx, dat, votedOn)
+			adjustment = -policy.PenaltyRepu
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = settleVotes(ctx, "VoteOnAppeal", req.EvaluatorsChaincode, dat, questionData, adjustment, "")
+		if err != nil {
+			return nil, err
+		}
+		decided = &AppealDecidedEvent{req.AnswerHashID, dat.Appeal.Status, dat.Status, acceptVotes, rejectVotes, adjustment, votedOn}
+	}
+
+	err = ctx.PutAnswer(dat)
+	if err != nil {
+		return nil, err
+	}
+	if accepted {
+		err = ctx.PutAcceptedAnswer(dat)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx.EmitEvent(EventAppealVoted, AppealVotedEvent{req.AnswerHashID, req.EvaluatorID, req.Accept, acceptVotes, rejectVotes, votedOn})
+	if decided != nil {
+		ctx.EmitEvent(EventAppealDecided, decided)
+	}
+	if accepted {
+		ctx.EmitEvent(EventAnswerAccepted, AnswerAcceptedEvent{req.AnswerHashID, dat.QuestionID, dat.AnsweredBy, dat.AttainedEvaluatorThumbsUp, dat.AttainedGraderVotes, votedOn})
+	}
+	return result, nil
+}
+
+// tallyAppeal the accept and reject votes of the appeal and the status they give it, OVERTURNED once a majority of the
+// panel voted to accept, UPHELD once a majority voted to reject or the whole panel voted without a majority to accept
+// and OPEN before
+func tallyAppeal(appeal *Appeal) (int, int, string) {
+	acceptVotes, rejectVotes := 0, 0
+	for _, vote := range appeal.Votes {
+		if vote.Accept {
+			acceptVotes++
+		} else {
+			rejectVotes++
+		}
+	}
+
+	majority := len(appeal.PanelEvaluatorIDs)/2 + 1
+	if acceptVotes >= majority {
+		return acceptVotes, rejectVotes, AppealStatusOverturned
+	}
+	if rejectVotes >= majority || len(appeal.Votes) == len(appeal.PanelEvaluatorIDs) {
+		return acceptVotes, rejectVotes, AppealStatusUpheld
+	}
+	return acceptVotes, rejectVotes, AppealStatusOpen
+}
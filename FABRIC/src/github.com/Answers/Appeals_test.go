@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestTallyAppeal(t *testing.T) {
+	tests := []struct {
+		name        string
+		panel       int
+		votes       []bool
+		acceptVotes int
+		rejectVotes int
+		status      string
+	}{
+		{name: "no votes", panel: 3, votes: nil, status: AppealStatusOpen},
+		{name: "one accept of three", panel: 3, votes: []bool{true}, acceptVotes: 1, status: AppealStatusOpen},
+		{name: "split of three", panel: 3, votes: []bool{true, false}, acceptVotes: 1, rejectVotes: 1, status: AppealStatusOpen},
+		{name: "majority accepts", panel: 3, votes: []bool{true, true}, acceptVotes: 2, status: AppealStatusOverturned},
+		{name: "majority rejects", panel: 3, votes: []bool{false, false}, rejectVotes: 2, status: AppealStatusUpheld},
+		{name: "half of four accepts", panel: 4, votes: []bool{true, true}, acceptVotes: 2, status: AppealStatusOpen},
+		{name: "tie of the whole panel", panel: 4, votes: []bool{true, true, false, false}, acceptVotes: 2, rejectVotes: 2, status: AppealStatusUpheld},
+		{name: "single panel evaluator accepts", panel: 1, votes: []bool{true}, acceptVotes: 1, status: AppealStatusOverturned},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			appeal := &Appeal{PanelEvaluatorIDs: make([]string, test.panel)}
+			for _, accept := range test.votes {
+				appeal.Votes = append(appeal.Votes, AppealVote{Accept: accept})
+			}
+			acceptVotes, rejectVotes, status := tallyAppeal(appeal)
+			if acceptVotes != test.acceptVotes || rejectVotes != test.rejectVotes || status != test.status {
+				t.Errorf("tallyAppeal(%d, %v) = %d %d %s, want %d %d %s", test.panel, test.votes, acceptVotes, rejectVotes, status,
+					test.acceptVotes, test.rejectVotes, test.status)
+			}
+		})
+	}
+}
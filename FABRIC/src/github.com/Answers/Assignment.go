@@ -0,0 +1,233 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Evaluator Assignment - once an admin sets AssignedEvaluators in the config, SubmitAnswer assigns that many eligible
+// evaluators to the answer, chosen deterministically from the answer's ledger state, and only they can give it a thumbs
+// up until their assignment expires and ReassignEvaluators replaces them
+// ============================================================================================================================
+
+// status of the assignment of an evaluator to an answer
+const (
+	AssignmentStatusPending   = "PENDING"
+	AssignmentStatusEvaluated = "EVALUATED"
+	AssignmentStatusExpired   = "EXPIRED"
+)
+
+// EventEvaluatorsAssigned is raised by SubmitAnswer and ReassignEvaluators, its payload is an EvaluatorsAssignedEvent
+const EventEvaluatorsAssigned = "EvaluatorsAssigned"
+
+// EvaluatorAssignment the assignment of an evaluator to an answer, due within the configured timeout
+type EvaluatorAssignment struct {
+	EvaluatorID string `json:"EvaluatorID"`
+	AssignedOn  string `json:"AssignedOn"`
+	DueOn       string `json:"DueOn"`
+	Status      string `json:"Status"`
+}
+
+// EvaluatorsAssignedEvent payload of EvaluatorsAssigned, ExpiredEvaluatorIDs are the assignments a reassignment replaced
+type EvaluatorsAssignedEvent struct {
+	AnswerHashID         string   `json:"AnswerHashID"`
+	AssignedEvaluatorIDs []string `json:"AssignedEvaluatorIDs"`
+	ExpiredEvaluatorIDs  []string `json:"ExpiredEvaluatorIDs"`
+	DueOn                string   `json:"DueOn"`
+}
+
+// ReassignEvaluatorsRequest request object of ReassignEvaluators
+type ReassignEvaluatorsRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" validate:"maxlength=64,format=chaincode"`
+	TechsChaincode      string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+}
+
+// assignEvaluators tops the assignments of the answer up to the evaluators it needs, picked from the eligible evaluators
+// never assigned to it before, it leaves writing the answer and raising the returned event to the caller
+func assignEvaluators(ctx TransactionContextInterface, evaluatorsChaincode string, techsChaincode string, question Question, answer *Answer, expired []string) (*EvaluatorsAssignedEvent, error) {
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+
+	// enough evaluators are assigned for the answer to attain the thumbs up its question requires
+	needed := config.AssignedEvaluators
+	if needed < question.RequiredEvaluatorThumbsUp {
+		needed = question.RequiredEvaluatorThumbsUp
+	}
+	assigned := map[string]bool{}
+	for _, assignment := range answer.AssignedEvaluators {
+		assigned[assignment.EvaluatorID] = true
+		if assignment.Status != AssignmentStatusExpired {
+			needed--
+		}
+	}
+
+	candidates, err := getEligibleEvaluators(ctx, evaluatorsChaincode, techsChaincode, question, answer, config, now, 0)
+	if err != nil {
+		return nil, err
+	}
+	var unassigned []string
+	for _, evaluatorID := range candidates {
+		if !assigned[evaluatorID] {
+			unassigned = append(unassigned, evaluatorID)
+		}
+	}
+
+	dueOn, err := common.AddMinutes(now, config.AssignmentTimeoutMinutes)
+	if err != nil {
+		return nil, err
+	}
+	selected := selectEvaluators(assignmentSeed(answer), unassigned, needed)
+	for _, evaluatorID := range selected {
+		answer.AssignedEvaluators = append(answer.AssignedEvaluators, EvaluatorAssignment{evaluatorID, now, dueOn, AssignmentStatusPending})
+	}
+	return &EvaluatorsAssignedEvent{answer.AnswerHashID, selected, expired, dueOn}, nil
+}
+
+// getEligibleEvaluators the ids of the evaluators holding the question's tech or one of its ancestors that would be
+// allowed to give the answer a thumbs up now with a qualifying reputation of at least minRepu, ordered by id
+func getEligibleEvaluators(ctx TransactionContextInterface, evaluatorsChaincode string, techsChaincode string, question Question, answer *Answer, config *Config, now string, minRepu int) ([]string, error) {
+	ancestors, err := getTechAncestors(ctx, techsChaincode, question.QuestionTech)
+	if err != nil {
+		return nil, err
+	}
+	techNames := []string{question.QuestionTech}
+	if config.ParentRepuPercent > 0 {
+		techNames = append(techNames, ancestors...)
+	}
+
+	evaluatorsBytes, err := ctx.CallChaincode(evaluatorsChaincode, "GetEvaluatorsByTechs", map[string][]string{"TechNames": techNames})
+	if err != nil {
+		return nil, err
+	}
+	var evaluators []Evaluator
+	err = json.Unmarshal(evaluatorsBytes, &evaluators)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall the evaluators of %s", question.QuestionTech)
+	}
+
+	var eligible []string
+	for _, evaluator := range evaluators {
+		if evaluator.LockedUntil != "" && now < evaluator.LockedUntil {
+			continue
+		}
+		evaluation, err := checkEvaluatorEligible(evaluator, "", question, answer, ancestors, config.ParentRepuPercent)
+		if err == nil && evaluation.QualifyingRepu >= minRepu {
+			eligible = append(eligible, evaluator.EvaluatorID)
+		}
+	}
+	return eligible, nil
+}
+
+// assignmentSeed the seed of the next assignment of the answer, its hash id, the number of assignments it had and the
+// due date of the last one, read from the ledger and not from the transaction, so whoever calls ReassignEvaluators
+// cannot steer the evaluators by choosing its transaction id
+func assignmentSeed(answer *Answer) string {
+	lastDueOn := ""
+	if len(answer.AssignedEvaluators) > 0 {
+		lastDueOn = answer.AssignedEvaluators[len(answer.AssignedEvaluators)-1].DueOn
+	}
+	return answer.AnswerHashID + ":" + strconv.Itoa(len(answer.AssignedEvaluators)) + ":" + lastDueOn
+}
+
+// selectEvaluators picks count of the candidates ranked by the sha256 of the seed and their id, every endorser of
+// the transaction picks the same evaluators while no one can choose them in advance
+func selectEvaluators(seed string, candidates []string, count int) []string {
+	if count <= 0 {
+		return []string{}
+	}
+	ranks := map[string]string{}
+	for _, evaluatorID := range candidates {
+		digest := sha256.Sum256([]byte(seed + "\x00" + evaluatorID))
+		ranks[evaluatorID] = hex.EncodeToString(digest[:])
+	}
+	ranked := append([]string{}, candidates...)
+	sort.Slice(ranked, func(i, j int) bool { return ranks[ranked[i]] < ranks[ranked[j]] })
+	if len(ranked) > count {
+		ranked = ranked[:count]
+	}
+	return ranked
+}
+
+// markAssignmentEvaluated records the thumbs up on the evaluator's assignment, FORBIDDEN when the answer only takes
+// assigned evaluators and the evaluator is not one of them or its assignment expired
+func markAssignmentEvaluated(answer *Answer, evaluatorID string, now string) error {
+	if !answer.AssignedEvaluatorsOnly {
+		return nil
+	}
+	for i, assignment := range answer.AssignedEvaluators {
+		if assignment.EvaluatorID != evaluatorID || assignment.Status == AssignmentStatusExpired {
+			continue
+		}
+		if assignment.Status == AssignmentStatusPending && now > assignment.DueOn {
+			return common.NewError(common.ErrForbidden, "the assignment of evaluator %s to the answer %s expired on %s", evaluatorID, answer.AnswerHashID, assignment.DueOn)
+		}
+		answer.AssignedEvaluators[i].Status = AssignmentStatusEvaluated
+		return nil
+	}
+	return common.NewError(common.ErrForbidden, "evaluator %s is not assigned to the answer %s", evaluatorID, answer.AnswerHashID)
+}
+
+// reassignEvaluators expires the assignments of the answer that are past due and assigns other evaluators in their place
+// or in place of the ones missing when the answer was submitted, anyone can call it since the answer's ledger state
+// picks them
+func reassignEvaluators(ctx TransactionContextInterface, req ReassignEvaluatorsRequest) error {
+	dat, err := getAnswerLedgerState(ctx, req.AnswerHashID)
+	if err != nil {
+		return err
+	}
+	dat, err = withAuthor(ctx, dat)
+	if err != nil {
+		return err
+	}
+	if !isOpenForEvaluation(dat) {
+		return common.NewError(common.ErrInvalidState, "the answer %s is %s", req.AnswerHashID, dat.Status)
+	}
+	if !dat.AssignedEvaluatorsOnly {
+		return common.NewError(common.ErrInvalidState, "no evaluators are assigned to the answer %s", req.AnswerHashID)
+	}
+
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	var expired []string
+	for i, assignment := range dat.AssignedEvaluators {
+		if assignment.Status == AssignmentStatusPending && now > assignment.DueOn {
+			dat.AssignedEvaluators[i].Status = AssignmentStatusExpired
+			expired = append(expired, assignment.EvaluatorID)
+		}
+	}
+
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, dat.QuestionID)
+	if err != nil {
+		return err
+	}
+	assignedEvent, err := assignEvaluators(ctx, req.EvaluatorsChaincode, req.TechsChaincode, questionData, dat, expired)
+	if err != nil {
+		return err
+	}
+	// an answer that got fewer evaluators than it needs is topped up once more became eligible
+	if len(expired) == 0 && len(assignedEvent.AssignedEvaluatorIDs) == 0 {
+		return common.NewError(common.ErrInvalidState, "no assignment of the answer %s is past due and no other evaluator is eligible", req.AnswerHashID)
+	}
+	err = ctx.PutAnswer(dat)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventEvaluatorsAssigned, assignedEvent)
+	return nil
+}
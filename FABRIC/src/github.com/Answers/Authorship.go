@@ -0,0 +1,321 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Blind Evaluation - once an admin sets BlindEvaluation in the config, the student of a submitted answer travels in the
+// transient map, is kept in the answer authors private data collection and the answer only shows an anonymous handle
+// until its evaluation is final, the Student chaincode only records the answered question then, the read and write
+// sets of the transactions still name the student, so it hides the student from the answer, not from the blocks
+// ============================================================================================================================
+
+// AuthorsCollection the private data collection holding the authors of blind answers, see collections_config.json, its
+// members are the organizations without evaluators
+const AuthorsCollection = "answerAuthors"
+
+// AuthorTransientKey the transient key the student of a blind answer travels under
+const AuthorTransientKey = "AnsweredBy"
+
+// BlindKeyTransientKey the transient key SetBlindEvaluationKey takes the key under
+const BlindKeyTransientKey = "BlindEvaluationKey"
+
+// blindKeyObjectType the key of the private data collection the blind evaluation key is stored under
+const blindKeyObjectType = "blindkey"
+
+// pendingAnswerObjectType a blind answer of a student to a question is marked pending under a composite key of the
+// keyed digest of both, so the marker stops a second answer without naming the student
+const pendingAnswerObjectType = "pendinganswer"
+
+// AnswerAuthor the student of a blind answer, kept out of the world state until the answer is final, the nonce keeps
+// the hash of the private record on the ledger from being matched against the known students
+type AnswerAuthor struct {
+	AnswerHashID         string `json:"AnswerHashID"`
+	AnsweredBy           string `json:"AnsweredBy"`
+	AnswererEnrollmentID string `json:"AnswererEnrollmentID,omitempty"`
+	// the Student chaincode recording the answered question once the answer is final, empty for a gold-standard
+	// answer and the blind answers submitted before the question was recorded at the reveal
+	StudentsChaincode string `json:"StudentsChaincode,omitempty"`
+	Nonce             string `json:"Nonce,omitempty"`
+}
+
+// GetAnswerAuthor reads the author of a blind answer from the private data collection, nil if it has none
+func (ctx *TransactionContext) GetAnswerAuthor(answerHashID string) (*AnswerAuthor, error) {
+	authorAsBytes, err := ctx.GetStub().GetPrivateData(AuthorsCollection, answerHashID)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding the author of - %s", answerHashID)
+	}
+	if authorAsBytes == nil {
+		return nil, nil
+	}
+
+	author := AnswerAuthor{}
+	err = json.Unmarshal(authorAsBytes, &author)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall the author of %s", answerHashID)
+	}
+	return &author, nil
+}
+
+// PutAnswerAuthor writes the author of a blind answer to the private data collection
+func (ctx *TransactionContext) PutAnswerAuthor(author *AnswerAuthor) error {
+	buff, err := json.Marshal(author)
+	if err != nil {
+		return common.InternalError(err, "unable to convert the author to json")
+	}
+	err = ctx.GetStub().PutPrivateData(AuthorsCollection, author.AnswerHashID, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write the author of - %s", author.AnswerHashID)
+	}
+	return nil
+}
+
+// DelAnswerAuthor removes the author of a revealed answer from the private data collection
+func (ctx *TransactionContext) DelAnswerAuthor(answerHashID string) error {
+	err := ctx.GetStub().DelPrivateData(AuthorsCollection, answerHashID)
+	if err != nil {
+		return common.InternalError(err, "unable to delete the author of - %s", answerHashID)
+	}
+	return nil
+}
+
+// GetBlindKey reads the blind evaluation key from the private data collection, empty if an admin did not set it
+func (ctx *TransactionContext) GetBlindKey() (string, error) {
+	blindKey, err := ctx.GetStub().CreateCompositeKey(blindKeyObjectType, []string{})
+	if err != nil {
+		return "", common.InternalError(err, "unable to create the blind evaluation key")
+	}
+	keyAsBytes, err := ctx.GetStub().GetPrivateData(AuthorsCollection, blindKey)
+	if err != nil {
+		return "", common.InternalError(err, "error in finding the blind evaluation key")
+	}
+	return string(keyAsBytes), nil
+}
+
+// PutBlindKey writes the blind evaluation key to the private data collection
+func (ctx *TransactionContext) PutBlindKey(key string) error {
+	blindKey, err := ctx.GetStub().CreateCompositeKey(blindKeyObjectType, []string{})
+	if err != nil {
+		return common.InternalError(err, "unable to create the blind evaluation key")
+	}
+	err = ctx.GetStub().PutPrivateData(AuthorsCollection, blindKey, []byte(key))
+	if err != nil {
+		return common.InternalError(err, "unable to write the blind evaluation key")
+	}
+	return nil
+}
+
+// HasPendingAnswer whether the marker of a pending blind answer is in the private data collection
+func (ctx *TransactionContext) HasPendingAnswer(marker string) (bool, error) {
+	pendingKey, err := ctx.GetStub().CreateCompositeKey(pendingAnswerObjectType, []string{marker})
+	if err != nil {
+		return false, common.InternalError(err, "unable to create the pending answer key")
+	}
+	pendingAsBytes, err := ctx.GetStub().GetPrivateData(AuthorsCollection, pendingKey)
+	if err != nil {
+		return false, common.InternalError(err, "error in finding the pending answer")
+	}
+	return pendingAsBytes != nil, nil
+}
+
+// PutPendingAnswer marks a blind answer pending in the private data collection
+func (ctx *TransactionContext) PutPendingAnswer(marker string) error {
+	pendingKey, err := ctx.GetStub().CreateCompositeKey(pendingAnswerObjectType, []string{marker})
+	if err != nil {
+		return common.InternalError(err, "unable to create the pending answer key")
+	}
+	// the value is never read, a key needs one to be stored
+	err = ctx.GetStub().PutPrivateData(AuthorsCollection, pendingKey, []byte{0x00})
+	if err != nil {
+		return common.InternalError(err, "unable to mark the answer pending")
+	}
+	return nil
+}
+
+// DelPendingAnswer removes the marker of a revealed answer from the private data collection
+func (ctx *TransactionContext) DelPendingAnswer(marker string) error {
+	pendingKey, err := ctx.GetStub().CreateCompositeKey(pendingAnswerObjectType, []string{marker})
+	if err != nil {
+		return common.InternalError(err, "unable to create the pending answer key")
+	}
+	err = ctx.GetStub().DelPrivateData(AuthorsCollection, pendingKey)
+	if err != nil {
+		return common.InternalError(err, "unable to delete the pending answer")
+	}
+	return nil
+}
+
+// setBlindEvaluationKey admin only, sets the key the blind answers are kept private with once, a new key would let a
+// student answer again while its blind answer is pending
+func setBlindEvaluationKey(ctx TransactionContextInterface) error {
+	err := common.AssertAdmin(ctx, "SetBlindEvaluationKey")
+	if err != nil {
+		return err
+	}
+	key, err := common.GetTransientSecret(ctx, "SetBlindEvaluationKey", BlindKeyTransientKey, 32, 128)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetBlindKey()
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return common.NewError(common.ErrAlreadyExists, "the blind evaluation key is already set")
+	}
+	return ctx.PutBlindKey(key)
+}
+
+// getAnsweredBy the student answering, while answers are evaluated blind it is taken from the transient map so the
+// request written to the ledger does not name it, and the request has to leave AnsweredBy out
+func getAnsweredBy(ctx TransactionContextInterface, function string, answeredBy string) (string, error) {
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return "", err
+	}
+	if !config.BlindEvaluation {
+		if answeredBy == "" {
+			return "", common.ValidationError(function, []common.FieldError{{Field: "AnsweredBy", Message: "is required while answers are not evaluated blind"}})
+		}
+		return answeredBy, nil
+	}
+	if answeredBy != "" {
+		return "", common.ValidationError(function, []common.FieldError{{Field: "AnsweredBy", Message: "must travel in the transient map while answers are evaluated blind"}})
+	}
+	fieldSchema := common.FieldSchema{Name: AuthorTransientKey, Type: common.TypeString, Required: true, MaxLength: 64, Format: common.FormatID}
+	return common.GetTransientValue(ctx, function, fieldSchema)
+}
+
+// keyedDigest the hex HMAC-SHA256 of the parts under the blind evaluation key, only the members of the collection can
+// compute it
+func keyedDigest(blindKey string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(blindKey))
+	mac.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// pendingAnswerMarker the marker of the pending blind answer of the student to the question, empty without a key
+func pendingAnswerMarker(ctx TransactionContextInterface, questionID string, studentID string) (string, error) {
+	blindKey, err := ctx.GetBlindKey()
+	if err != nil || blindKey == "" {
+		return "", err
+	}
+	return keyedDigest(blindKey, pendingAnswerObjectType, questionID, studentID), nil
+}
+
+// assertNotAnswered fails with INVALID_STATE when the student answered the question, the answered questions recorded by
+// the Student chaincode miss the blind answers still pending, which are marked in the private data collection
+func assertNotAnswered(ctx TransactionContextInterface, student Student, questionID string) error {
+	alreadyAnswered := stringInSlice(questionID, student.AnsweredQuestions)
+	if !alreadyAnswered {
+		marker, err := pendingAnswerMarker(ctx, questionID, student.StudentID)
+		if err != nil {
+			return err
+		}
+		if marker != "" {
+			alreadyAnswered, err = ctx.HasPendingAnswer(marker)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if alreadyAnswered {
+		return common.NewError(common.ErrInvalidState, "already answered cant repeat - %s", questionID)
+	}
+	return nil
+}
+
+// isAuthorHidden whether the answer is blind and its author not revealed yet
+func isAuthorHidden(answer *Answer) bool {
+	return answer.AuthorHandle != "" && answer.AuthorRevealedOn == ""
+}
+
+// authorHandle the anonymous handle evaluators see in place of the student, it tells the answers apart without
+// linking the answers of a student together
+func authorHandle(txID string, answerHashID string) string {
+	digest := sha256.Sum256([]byte(txID + "\x00" + answerHashID))
+	return "anon-" + hex.EncodeToString(digest[:8])
+}
+
+// hideAuthor makes the answer blind, its author goes to the private data collection and PutAnswer leaves it out, the
+// answer of a student is marked pending until revealAuthor has the Student chaincode record it
+func hideAuthor(ctx TransactionContextInterface, answer *Answer, studentsChaincode string) error {
+	blindKey, err := ctx.GetBlindKey()
+	if err != nil {
+		return err
+	}
+	if blindKey == "" {
+		return common.NewError(common.ErrInvalidState, "answers are evaluated blind once an admin set the key with SetBlindEvaluationKey")
+	}
+	if studentsChaincode != "" {
+		err = ctx.PutPendingAnswer(keyedDigest(blindKey, pendingAnswerObjectType, answer.QuestionID, answer.AnsweredBy))
+		if err != nil {
+			return err
+		}
+	}
+
+	answer.AuthorHandle = authorHandle(ctx.GetStub().GetTxID(), answer.AnswerHashID)
+	return ctx.PutAnswerAuthor(&AnswerAuthor{
+		AnswerHashID:         answer.AnswerHashID,
+		AnsweredBy:           answer.AnsweredBy,
+		AnswererEnrollmentID: answer.AnswererEnrollmentID,
+		StudentsChaincode:    studentsChaincode,
+		Nonce:                keyedDigest(blindKey, "author", answer.AnswerHashID),
+	})
+}
+
+// withAuthor the answer with the author of a blind answer read back from the private data collection, for the checks
+// that need the student, the answer as stored is left untouched so it can be returned without the author
+func withAuthor(ctx TransactionContextInterface, answer *Answer) (*Answer, error) {
+	if !isAuthorHidden(answer) {
+		return answer, nil
+	}
+	author, err := ctx.GetAnswerAuthor(answer.AnswerHashID)
+	if err != nil {
+		return nil, err
+	}
+	if author == nil {
+		return nil, common.NewError(common.ErrInternal, "the author of the blind answer %s is missing from %s", answer.AnswerHashID, AuthorsCollection)
+	}
+
+	authored := *answer
+	authored.AnsweredBy = author.AnsweredBy
+	authored.AnswererEnrollmentID = author.AnswererEnrollmentID
+	return &authored, nil
+}
+
+// revealAuthor makes the author of a blind answer public once its evaluation is final and has the Student chaincode
+// record the answered question, the answer must have been read through withAuthor so PutAnswer writes the author back
+// to the world state
+func revealAuthor(ctx TransactionContextInterface, answer *Answer, revealedOn string) error {
+	if !isAuthorHidden(answer) {
+		return nil
+	}
+	author, err := ctx.GetAnswerAuthor(answer.AnswerHashID)
+	if err != nil {
+		return err
+	}
+	if author != nil && author.StudentsChaincode != "" {
+		_, err = ctx.CallChaincode(author.StudentsChaincode, "UpdateAnsweredQuestions", map[string]string{"StudentID": author.AnsweredBy, "QuestionID": answer.QuestionID})
+		if err != nil {
+			return common.LiftDependencyError(err, []string{common.ErrNotFound, common.ErrInvalidState}, "student %s cannot answer the question %s", author.AnsweredBy, answer.QuestionID)
+		}
+		marker, err := pendingAnswerMarker(ctx, answer.QuestionID, author.AnsweredBy)
+		if err != nil {
+			return err
+		}
+		err = ctx.DelPendingAnswer(marker)
+		if err != nil {
+			return err
+		}
+	}
+	answer.AuthorRevealedOn = revealedOn
+	return ctx.DelAnswerAuthor(answer.AnswerHashID)
+}
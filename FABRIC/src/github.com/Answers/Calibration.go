@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Calibration - once an admin sets AccuracyTracking in the config, every thumbs up is scored against the outcome of its
+// answer through RecordEvaluationOutcome of the Evaluator chaincode: right away for the gold-standard answers an admin
+// seeds with a known outcome, which are stored and assigned like any other answer and never accepted, and once the
+// outcome is final for the others, AccuracyWeighting then weighs every thumbs up by the accuracy of its evaluator
+// ============================================================================================================================
+
+// goldObjectType the known outcome of a gold-standard answer is kept in the private data collection of the authors under
+// a composite key of the keyed digest of the answer, so neither the answer nor the key hashes on the ledger tell it apart
+const goldObjectType = "gold"
+
+// ExpectedOutcomeTransientKey the transient key SeedGoldAnswer takes the expected outcome under
+const ExpectedOutcomeTransientKey = "ExpectedOutcome"
+
+// outcome of a scored thumbs up, as declared by the Evaluator chaincode
+const (
+	VoteOutcomeCorrect   = "CORRECT"
+	VoteOutcomeIncorrect = "INCORRECT"
+	VoteOutcomeRevised   = "REVISED"
+)
+
+// events of the Evaluator chaincode raised again with the changes its functions settling an outcome return, the
+// events raised by a called chaincode never reach the client
+const (
+	EventAccuracyChanged   = "AccuracyChanged"
+	EventReputationChanged = "ReputationChanged"
+)
+
+// EvaluatorChanges the changes the Evaluator chaincode returns, the payloads of its AccuracyChanged and
+// ReputationChanged events as it raised them
+type EvaluatorChanges struct {
+	AccuracyChanged   json.RawMessage `json:"AccuracyChanged,omitempty"`
+	ReputationChanged json.RawMessage `json:"ReputationChanged,omitempty"`
+}
+
+// GoldStandard the outcome an admin expects of a gold-standard answer, ACCEPTED or REJECTED, the nonce keeps the hash of
+// the private record on the ledger from being matched against both outcomes
+type GoldStandard struct {
+	AnswerHashID    string `json:"AnswerHashID"`
+	QuestionID      string `json:"QuestionID"`
+	ExpectedOutcome string `json:"ExpectedOutcome"`
+	SeededOn        string `json:"SeededOn"`
+	Nonce           string `json:"Nonce,omitempty"`
+}
+
+// SeedGoldAnswerRequest request object of SeedGoldAnswer, AnsweredBy is the student the answer is shown under while
+// blind evaluation is off, the expected outcome travels in the transient map
+type SeedGoldAnswerRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" metadata:",optional" validate:"maxlength=64,format=chaincode"`
+	TechsChaincode      string `json:"TechsChaincode" metadata:",optional" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	AnswerCID           string `json:"AnswerCID" validate:"maxlength=128,format=cid"`
+	AnsweredBy          string `json:"AnsweredBy" metadata:",optional" validate:"maxlength=64,format=id"`
+	QuestionID          string `json:"QuestionID" validate:"format=hash"`
+}
+
+// goldKey the key of the gold standard of the answer in the private data collection, empty without a blind evaluation
+// key since no answer can be seeded then
+func (ctx *TransactionContext) goldKey(answerHashID string) (string, error) {
+	blindKey, err := ctx.GetBlindKey()
+	if err != nil || blindKey == "" {
+		return "", err
+	}
+	goldKey, err := ctx.GetStub().CreateCompositeKey(goldObjectType, []string{keyedDigest(blindKey, goldObjectType, answerHashID)})
+	if err != nil {
+		return "", common.InternalError(err, "unable to create the gold-standard key for %s", answerHashID)
+	}
+	return goldKey, nil
+}
+
+// GetGoldStandard reads the known outcome of a gold-standard answer from the private data collection, nil for any
+// other answer
+func (ctx *TransactionContext) GetGoldStandard(answerHashID string) (*GoldStandard, error) {
+	goldKey, err := ctx.goldKey(answerHashID)
+	if err != nil || goldKey == "" {
+		return nil, err
+	}
+	goldAsBytes, err := ctx.GetStub().GetPrivateData(AuthorsCollection, goldKey)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding the gold standard of - %s", answerHashID)
+	}
+	if goldAsBytes == nil {
+		return nil, nil
+	}
+
+	gold := GoldStandard{}
+	err = json.Unmarshal(goldAsBytes, &gold)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall the gold standard of %s", answerHashID)
+	}
+	return &gold, nil
+}
+
+// PutGoldStandard writes the known outcome of a gold-standard answer to the private data collection
+func (ctx *TransactionContext) PutGoldStandard(gold *GoldStandard) error {
+	goldKey, err := ctx.goldKey(gold.AnswerHashID)
+	if err != nil {
+		return err
+	}
+	if goldKey == "" {
+		return common.NewError(common.ErrInvalidState, "gold-standard answers are seeded once an admin set the key with SetBlindEvaluationKey")
+	}
+	buff, err := json.Marshal(gold)
+	if err != nil {
+		return common.InternalError(err, "unable to convert the gold standard to json")
+	}
+	err = ctx.GetStub().PutPrivateData(AuthorsCollection, goldKey, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write the gold standard of - %s", gold.AnswerHashID)
+	}
+	return nil
+}
+
+// seedGoldAnswer admin only, stores a gold-standard answer with its known outcome, submitted, made blind and assigned
+// to evaluators as SubmitAnswer does so it is mixed into their work, the outcome is taken from the transient map and
+// kept in the private data collection so evaluators cannot read it from the ledger
+func seedGoldAnswer(ctx TransactionContextInterface, req SeedGoldAnswerRequest) error {
+	fieldErrors := common.CheckCIDBinding("AnswerCID", req.AnswerCID, "AnswerHashID", req.AnswerHashID)
+	if len(fieldErrors) > 0 {
+		return common.ValidationError("SeedGoldAnswer", fieldErrors)
+	}
+	err := common.AssertAdmin(ctx, "SeedGoldAnswer")
+	if err != nil {
+		return err
+	}
+	fieldSchema := common.FieldSchema{Name: ExpectedOutcomeTransientKey, Type: common.TypeString, Required: true, MaxLength: 16}
+	expectedOutcome, err := common.GetTransientValue(ctx, "SeedGoldAnswer", fieldSchema)
+	if err != nil {
+		return err
+	}
+	if expectedOutcome != AnswerStatusAccepted && expectedOutcome != AnswerStatusRejected {
+		return common.ValidationError("SeedGoldAnswer", []common.FieldError{{Field: ExpectedOutcomeTransientKey, Message: "must be ACCEPTED or REJECTED"}})
+	}
+	answeredBy, err := getAnsweredBy(ctx, "SeedGoldAnswer", req.AnsweredBy)
+	if err != nil {
+		return err
+	}
+	blindKey, err := ctx.GetBlindKey()
+	if err != nil {
+		return err
+	}
+	if blindKey == "" {
+		return common.NewError(common.ErrInvalidState, "gold-standard answers are seeded once an admin set the key with SetBlindEvaluationKey")
+	}
+
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return err
+	}
+	if !config.AccuracyTracking {
+		return common.NewError(common.ErrInvalidState, "gold-standard answers are only seeded while the accuracy of evaluators is tracked")
+	}
+	existing, err := ctx.GetAnswer(req.AnswerHashID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return common.NewError(common.ErrAlreadyExists, "This answer already exists - %s", req.AnswerHashID)
+	}
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, req.QuestionID)
+	if err != nil {
+		return err
+	}
+	seededOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+
+	answerObject := CreateAnswerObject(req.AnswerHashID, req.AnswerCID, answeredBy, req.QuestionID, seededOn, "")
+	answerObject.ContentHashID = req.AnswerHashID
+	var assignedEvent *EvaluatorsAssignedEvent
+	if config.AssignedEvaluators > 0 {
+		if req.EvaluatorsChaincode == "" {
+			fieldErrors = append(fieldErrors, common.FieldError{Field: "EvaluatorsChaincode", Message: "is required while evaluators are assigned to answers"})
+		}
+		if req.TechsChaincode == "" {
+			fieldErrors = append(fieldErrors, common.FieldError{Field: "TechsChaincode", Message: "is required while evaluators are assigned to answers"})
+		}
+		if len(fieldErrors) > 0 {
+			return common.ValidationError("SeedGoldAnswer", fieldErrors)
+		}
+		answerObject.AssignedEvaluatorsOnly = true
+		assignedEvent, err = assignEvaluators(ctx, req.EvaluatorsChaincode, req.TechsChaincode, questionData, &answerObject, nil)
+		if err != nil {
+			return err
+		}
+	}
+	submittedEvent := AnswerSubmittedEvent{req.AnswerHashID, req.AnswerCID, answeredBy, req.QuestionID, seededOn, ""}
+	if config.BlindEvaluation {
+		err = hideAuthor(ctx, &answerObject, "")
+		if err != nil {
+			return err
+		}
+		submittedEvent.AnsweredBy = ""
+		submittedEvent.AuthorHandle = answerObject.AuthorHandle
+	}
+
+	err = ctx.PutAnswer(&answerObject)
+	if err != nil {
+		return err
+	}
+	err = ctx.PutGoldStandard(&GoldStandard{
+		AnswerHashID:    req.AnswerHashID,
+		QuestionID:      req.QuestionID,
+		ExpectedOutcome: expectedOutcome,
+		SeededOn:        seededOn,
+		Nonce:           keyedDigest(blindKey, "goldnonce", req.AnswerHashID),
+	})
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventAnswerSubmitted, submittedEvent)
+	if assignedEvent != nil {
+		ctx.EmitEvent(EventEvaluatorsAssigned, assignedEvent)
+	}
+	return nil
+}
+
+// knownOutcome the outcome the thumbs up of the answer is scored with as it is given: the expected outcome of a
+// gold-standard answer, the settled outcome once the thumbs up made the evaluation of the answer final, empty while
+// the outcome is open or accuracy is not tracked
+func knownOutcome(ctx TransactionContextInterface, answer *Answer, config *Config) (string, bool, error) {
+	if !config.AccuracyTracking {
+		return "", false, nil
+	}
+	gold, err := ctx.GetGoldStandard(answer.AnswerHashID)
+	if err != nil {
+		return "", false, err
+	}
+	if gold != nil {
+		if gold.ExpectedOutcome == AnswerStatusAccepted {
+			return VoteOutcomeCorrect, true, nil
+		}
+		return VoteOutcomeIncorrect, true, nil
+	}
+	return settledOutcome(answer), false, nil
+}
+
+// settledOutcome the outcome the thumbs up of the answer are scored with, derived from the status the answer is in:
+// CORRECT once accepted, REVISED once accepted on the appeal of its rejection, INCORRECT once rejected, empty while the
+// evaluation is open and for a rejection upheld on appeal, whose thumbs up were scored when the answer was rejected
+func settledOutcome(answer *Answer) string {
+	previousStatus := ""
+	if answer.Appeal != nil {
+		previousStatus = answer.Appeal.PreviousStatus
+	}
+	switch answer.Status {
+	case AnswerStatusAccepted:
+		if previousStatus == AnswerStatusRejected {
+			return VoteOutcomeRevised
+		}
+		return VoteOutcomeCorrect
+	case AnswerStatusRejected:
+		if previousStatus == AnswerStatusRejected {
+			return ""
+		}
+		return VoteOutcomeIncorrect
+	}
+	return ""
+}
+
+// settleVotes scores the thumbs up of the answer with the outcome settled by its status while accuracy is tracked and
+// adjusts the reputation of their evaluators by repuDelta in the tech that qualified them, see RepuPolicy.go, through a
+// single call of the Evaluator chaincode per evaluator since a record can only be written once per transaction,
+// skipEvaluatorID is the evaluator already written by the transaction
+func settleVotes(ctx TransactionContextInterface, function string, evaluatorsChaincode string, answer *Answer, question Question, repuDelta int, skipEvaluatorID string) error {
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return err
+	}
+	outcome := ""
+	if config.AccuracyTracking {
+		outcome = settledOutcome(answer)
+	}
+	if outcome == "" && repuDelta == 0 {
+		return nil
+	}
+	if evaluatorsChaincode == "" {
+		return common.ValidationError(function, []common.FieldError{{Field: "EvaluatorsChaincode", Message: "is required while the accuracy or reputation of evaluators is settled with the outcome"}})
+	}
+
+	for _, evaluatorID := range answer.EvaluatedBy {
+		if evaluatorID == skipEvaluatorID {
+			continue
+		}
+		techName := getQualifiedTech(answer, question, evaluatorID)
+		var changesAsBytes []byte
+		if outcome == "" {
+			changesAsBytes, err = ctx.CallChaincode(evaluatorsChaincode, "AdjustEvaluatorRepu", map[string]interface{}{"EvaluatorID": evaluatorID, "TechName": techName, "Delta": repuDelta, "AnswerHashID": answer.AnswerHashID})
+		} else {
+			scored := map[string]interface{}{"EvaluatorID": evaluatorID, "AnswerHashID": answer.AnswerHashID, "Outcome": outcome}
+			if repuDelta != 0 {
+				scored["TechName"] = techName
+				scored["RepuDelta"] = repuDelta
+			}
+			changesAsBytes, err = ctx.CallChaincode(evaluatorsChaincode, "RecordEvaluationOutcome", scored)
+		}
+		if err != nil {
+			return common.LiftDependencyError(err, []string{common.ErrNotFound, common.ErrInvalidState}, "unable to settle the thumbs up of evaluator %s", evaluatorID)
+		}
+		err = emitEvaluatorChanges(ctx, changesAsBytes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitEvaluatorChanges raises the changes a function of the Evaluator chaincode returned with the events of the
+// transaction
+func emitEvaluatorChanges(ctx TransactionContextInterface, changesAsBytes []byte) error {
+	if len(changesAsBytes) == 0 {
+		return nil
+	}
+	changes := EvaluatorChanges{}
+	err := json.Unmarshal(changesAsBytes, &changes)
+	if err != nil {
+		return common.InternalError(err, "unable to unmarshall the changes of the evaluator")
+	}
+	if changes.AccuracyChanged != nil {
+		ctx.EmitEvent(EventAccuracyChanged, changes.AccuracyChanged)
+	}
+	if changes.ReputationChanged != nil {
+		ctx.EmitEvent(EventReputationChanged, changes.ReputationChanged)
+	}
+	return nil
+}
+
+// getQualifiedTech the tech that qualified the evaluator for its thumbs up, the question's tech for answers evaluated
+// before evaluations were recorded
+func getQualifiedTech(answer *Answer, question Question, evaluatorID string) string {
+	for _, evaluation := range answer.Evaluations {
+		if evaluation.EvaluatorID == evaluatorID {
+			return evaluation.QualifiedTech
+		}
+	}
+	return question.QuestionTech
+}
+
+// weighThumbsUp the thumbs up of the answer in percent of a thumbs up, each weighs the accuracy its evaluator had
+// when giving it once CalibrationVotes of the evaluator's votes were scored and a full thumbs up before
+func weighThumbsUp(answer *Answer) int {
+	weighted := 0
+	for _, evaluatorID := range answer.EvaluatedBy {
+		weight := 100
+		for _, evaluation := range answer.Evaluations {
+			if evaluation.EvaluatorID == evaluatorID && evaluation.Calibrated {
+				weight = evaluation.AccuracyPercent
+			}
+		}
+		weighted = weighted + weight
+	}
+	return weighted
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Commit Reveal - a question with a close time takes committed answers, the student commits to the salted hash of its
+// answer's CID before the question closes and reveals the CID and the salt after it, so no answer can be fetched from
+// IPFS and copied while the question is open
+// ============================================================================================================================
+
+// commitmentObjectType the commitment of a student to an answer is stored under a composite key of the question and
+// the student, so the answer hash id, which gives away the CID, stays unknown until the reveal
+const commitmentObjectType = "commitment"
+
+// EventAnswerCommitted is raised by CommitAnswer, its payload is the AnswerCommitment
+const EventAnswerCommitted = "AnswerCommitted"
+
+// AnswerCommitment the salted hash of the CID of the answer a student will reveal once the question closes
+type AnswerCommitment struct {
+	QuestionID  string `json:"QuestionID"`
+	CommittedBy string `json:"CommittedBy"`
+	Commitment  string `json:"Commitment"`
+	CommittedOn string `json:"CommittedOn"`
+}
+
+// CommitAnswerRequest request object of CommitAnswer, Commitment is the hex sha256 of QuestionID, AnsweredBy, the
+// AnswerCID and the salt joined by ':', so a commitment copied from another student or question does not reveal
+type CommitAnswerRequest struct {
+	QuestionsChaincode string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	StudentsChaincode  string `json:"StudentsChaincode" validate:"maxlength=64,format=chaincode"`
+	QuestionID         string `json:"QuestionID" validate:"format=hash"`
+	AnsweredBy         string `json:"AnsweredBy" validate:"maxlength=64,format=id"`
+	Commitment         string `json:"Commitment" validate:"format=hash"`
+}
+
+// RevealAnswerRequest request object of RevealAnswer, the SubmitAnswer request and the salt of the commitment
+type RevealAnswerRequest struct {
+	SubmitAnswerRequest
+	Salt string `json:"Salt" validate:"minlength=16,maxlength=128"`
+}
+
+// GetAnswerCommitment reads the commitment of the student to an answer to the question, nil if it has none
+func (ctx *TransactionContext) GetAnswerCommitment(questionID string, studentID string) (*AnswerCommitment, error) {
+	commitmentKey, err := ctx.GetStub().CreateCompositeKey(commitmentObjectType, []string{questionID, studentID})
+	if err != nil {
+		return nil, common.InternalError(err, "unable to create the commitment key for %s", questionID)
+	}
+	commitmentAsBytes, err := ctx.GetStub().GetState(commitmentKey)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding the commitment of %s to %s", studentID, questionID)
+	}
+	if commitmentAsBytes == nil {
+		return nil, nil
+	}
+
+	commitment := AnswerCommitment{}
+	err = json.Unmarshal(commitmentAsBytes, &commitment)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall the commitment of %s to %s", studentID, questionID)
+	}
+	return &commitment, nil
+}
+
+// PutAnswerCommitment writes the commitment against its question and student, replacing an earlier one
+func (ctx *TransactionContext) PutAnswerCommitment(commitment *AnswerCommitment) error {
+	commitmentKey, err := ctx.GetStub().CreateCompositeKey(commitmentObjectType, []string{commitment.QuestionID, commitment.CommittedBy})
+	if err != nil {
+		return common.InternalError(err, "unable to create the commitment key for %s", commitment.QuestionID)
+	}
+	buff, err := json.Marshal(commitment)
+	if err != nil {
+		return common.InternalError(err, "unable to convert the commitment to json")
+	}
+	err = ctx.GetStub().PutState(commitmentKey, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write the commitment of %s to %s", commitment.CommittedBy, commitment.QuestionID)
+	}
+	return nil
+}
+
+// DelAnswerCommitment removes the commitment once it is revealed
+func (ctx *TransactionContext) DelAnswerCommitment(questionID string, studentID string) error {
+	commitmentKey, err := ctx.GetStub().CreateCompositeKey(commitmentObjectType, []string{questionID, studentID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the commitment key for %s", questionID)
+	}
+	err = ctx.GetStub().DelState(commitmentKey)
+	if err != nil {
+		return common.InternalError(err, "unable to delete the commitment of %s to %s", studentID, questionID)
+	}
+	return nil
+}
+
+// commitAnswer stores the commitment of the student to an answer to a question with a close time while it is open,
+// a student can replace its commitment until then
+func commitAnswer(ctx TransactionContextInterface, req CommitAnswerRequest) (*common.AuthenticationResult, error) {
+	studentData, result, err := authenticateStudent(ctx, req.StudentsChaincode, req.AnsweredBy)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, req.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	if questionData.ClosesOn == "" {
+		return nil, common.NewError(common.ErrInvalidState, "the question %s has no close time, its answers are submitted with SubmitAnswer", req.QuestionID)
+	}
+	committedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	if committedOn >= questionData.ClosesOn {
+		return nil, common.NewError(common.ErrInvalidState, "the question %s closed on %s", req.QuestionID, questionData.ClosesOn)
+	}
+	err = assertNotAnswered(ctx, studentData, req.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPrerequisitesMet(ctx, studentData, questionData)
+	if err != nil {
+		return nil, err
+	}
+
+	commitment := &AnswerCommitment{req.QuestionID, req.AnsweredBy, req.Commitment, committedOn}
+	err = ctx.PutAnswerCommitment(commitment)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitEvent(EventAnswerCommitted, commitment)
+	return result, nil
+}
+
+// revealAnswer submits the committed answer once its question closed, the CID and the salt have to match the commitment
+func revealAnswer(ctx TransactionContextInterface, req RevealAnswerRequest) (*common.AuthenticationResult, error) {
+	answeredBy, err := getAnsweredBy(ctx, "RevealAnswer", req.AnsweredBy)
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := ctx.GetAnswerCommitment(req.QuestionID, answeredBy)
+	if err != nil {
+		return nil, err
+	}
+	if commitment == nil {
+		return nil, common.NewError(common.ErrNotFound, "student %s has no commitment to an answer to the question %s", answeredBy, req.QuestionID)
+	}
+	if !strings.EqualFold(commitmentDigest(req.QuestionID, answeredBy, req.AnswerCID, req.Salt), commitment.Commitment) {
+		return nil, common.ValidationError("RevealAnswer", []common.FieldError{{Field: "Salt", Message: "AnswerCID and Salt do not match the commitment"}})
+	}
+
+	result, err := submitAnswer(ctx, "RevealAnswer", req.SubmitAnswerRequest, commitment)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	err = ctx.DelAnswerCommitment(req.QuestionID, answeredBy)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// assertAnswerWindow fails with INVALID_STATE when the answer is submitted out of turn: in the open to a question with a
+// close time, or revealed before the question closed
+func assertAnswerWindow(question Question, commitment *AnswerCommitment, now string) error {
+	if commitment == nil && question.ClosesOn != "" {
+		return common.NewError(common.ErrInvalidState, "the question %s takes committed answers, commit with CommitAnswer before %s and reveal with RevealAnswer after it", question.QuestionHashID, question.ClosesOn)
+	}
+	if commitment != nil && now < question.ClosesOn {
+		return common.NewError(common.ErrInvalidState, "the answers to the question %s are revealed once it closes on %s", question.QuestionHashID, question.ClosesOn)
+	}
+	return nil
+}
+
+// commitmentDigest the hex sha256 of the question hash id, the student id, the CID and the salt joined by ':', only the
+// salt can hold a ':'
+func commitmentDigest(questionID string, answeredBy string, answerCID string, salt string) string {
+	digest := sha256.Sum256([]byte(questionID + ":" + answeredBy + ":" + answerCID + ":" + salt))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Common"
+)
+
+func TestCommitmentDigest(t *testing.T) {
+	questionID := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	answerCID := "QmaozNR7DZHQK1ZcU9p7QdrshMvXqWK6gpu5rmrkPdT3L4"
+	tests := []struct {
+		name       string
+		questionID string
+		answeredBy string
+		answerCID  string
+		salt       string
+		expected   string
+	}{
+		{name: "question, student, CID and salt", questionID: questionID, answeredBy: "s1", answerCID: answerCID, salt: "pepper", expected: "c9f2301b100962b2311abd960b277bc3a59fd0fefd57bd6b363e9d41fc053ab8"},
+		{name: "empty", expected: "f1ae2a75ed1f99721f02ef869e2fb3d4df102fdd73e2d00b424a222f9c1ea69c"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			digest := commitmentDigest(test.questionID, test.answeredBy, test.answerCID, test.salt)
+			if digest != test.expected {
+				t.Errorf("commitmentDigest(%q, %q, %q, %q) = %s, want %s", test.questionID, test.answeredBy, test.answerCID, test.salt, digest, test.expected)
+			}
+		})
+	}
+
+	digest := commitmentDigest(questionID, "s1", answerCID, "pepper")
+	if digest == commitmentDigest(questionID, "s2", answerCID, "pepper") {
+		t.Errorf("commitmentDigest does not bind the student")
+	}
+	if digest == commitmentDigest("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", "s1", answerCID, "pepper") {
+		t.Errorf("commitmentDigest does not bind the question")
+	}
+	if commitmentDigest(questionID, "s1", "ab", "c") == commitmentDigest(questionID, "s1", "a", "bc") {
+		t.Errorf("commitmentDigest does not separate the CID from the salt")
+	}
+}
+
+func TestAssertAnswerWindow(t *testing.T) {
+	openQuestion := Question{QuestionHashID: "q1"}
+	closingQuestion := Question{QuestionHashID: "q2", ClosesOn: "20240101120000"}
+	commitment := &AnswerCommitment{QuestionID: "q2", CommittedBy: "s1", CommittedOn: "20240101110000"}
+	tests := []struct {
+		name       string
+		question   Question
+		commitment *AnswerCommitment
+		now        string
+		invalid    bool
+	}{
+		{name: "submitted to an open question", question: openQuestion, commitment: nil, now: "20240101130000"},
+		{name: "submitted to a closing question", question: closingQuestion, commitment: nil, now: "20240101110000", invalid: true},
+		{name: "submitted after the question closed", question: closingQuestion, commitment: nil, now: "20240101130000", invalid: true},
+		{name: "revealed before the question closes", question: closingQuestion, commitment: commitment, now: "20240101115959", invalid: true},
+		{name: "revealed when the question closes", question: closingQuestion, commitment: commitment, now: "20240101120000"},
+		{name: "revealed after the question closed", question: closingQuestion, commitment: commitment, now: "20240102120000"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := assertAnswerWindow(test.question, test.commitment, test.now)
+			if !test.invalid {
+				if err != nil {
+					t.Fatalf("assertAnswerWindow(%s) failed - %s", test.now, err)
+				}
+				return
+			}
+			chaincodeError, ok := err.(*common.ChaincodeError)
+			if !ok || chaincodeError.Code != common.ErrInvalidState {
+				t.Errorf("assertAnswerWindow(%s) = %v, want a %s envelope", test.now, err, common.ErrInvalidState)
+			}
+		})
+	}
+}
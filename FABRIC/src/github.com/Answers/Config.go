@@ -0,0 +1,70 @@
+package main
+
+import (
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Configuration - one record per chaincode, changed by an admin through SetConfig
+// ============================================================================================================================
+
+// Config the settings of the chaincode, the defaults apply until an admin sets it, the settings that cannot be 0 are
+// required by SetConfig
+type Config struct {
+	// the percent of an evaluator's reputation in a parent tech that counts for its sub-techs, applied once per level,
+	// 0 only lets the reputation in the question's own tech count
+	ParentRepuPercent int `json:"ParentRepuPercent" metadata:",optional" validate:"minimum=0,maximum=100"`
+	// the evaluators assigned to every submitted answer, at least the thumbs up its question requires, 0 lets any
+	// eligible evaluator give a thumbs up
+	AssignedEvaluators       int `json:"AssignedEvaluators" metadata:",optional" validate:"minimum=0,maximum=50"`
+	AssignmentTimeoutMinutes int `json:"AssignmentTimeoutMinutes" validate:"minimum=1,maximum=43200"`
+	// hides the student of the answers submitted while it is on from the evaluators until the answer is accepted
+	BlindEvaluation bool `json:"BlindEvaluation" metadata:",optional"`
+	// the votes a grader result counts for once it passed GraderPassPercent of its tests, 0 only records the results
+	GraderVoteWeight  int `json:"GraderVoteWeight" metadata:",optional" validate:"minimum=0,maximum=100"`
+	GraderPassPercent int `json:"GraderPassPercent" validate:"minimum=1,maximum=100"`
+	// the similarity score in percent from which an attested answer is disputed, 0 only records the attestations
+	SimilarityThreshold int `json:"SimilarityThreshold" metadata:",optional" validate:"minimum=0,maximum=100"`
+	// an answer pending for AppealAfterMinutes can be appealed, its panel has AppealPanelSize evaluators qualified by
+	// AppealPanelRepuPercent of the reputation its question requires
+	AppealAfterMinutes     int `json:"AppealAfterMinutes" validate:"minimum=1,maximum=525600"`
+	AppealPanelSize        int `json:"AppealPanelSize" validate:"minimum=1,maximum=15"`
+	AppealPanelRepuPercent int `json:"AppealPanelRepuPercent" validate:"minimum=100,maximum=1000"`
+	// the reputation the evaluators of an answer gain once it is accepted and lose once an appeal upholds its rejection,
+	// for the techs without a policy of their own, see RepuPolicy.go, 0 leaves their reputation as it is
+	RewardRepu  int `json:"RewardRepu" metadata:",optional" validate:"minimum=0,maximum=1000"`
+	PenaltyRepu int `json:"PenaltyRepu" metadata:",optional" validate:"minimum=0,maximum=1000"`
+	// scores every thumbs up against the outcome of its answer through the Evaluator chaincode, AccuracyWeighting weighs
+	// every thumbs up by the accuracy of its evaluator once CalibrationVotes of the evaluator's votes were scored
+	AccuracyTracking  bool `json:"AccuracyTracking" metadata:",optional"`
+	AccuracyWeighting bool `json:"AccuracyWeighting" metadata:",optional"`
+	CalibrationVotes  int  `json:"CalibrationVotes" validate:"minimum=1,maximum=1000"`
+}
+
+var defaultConfig = Config{
+	ParentRepuPercent:        0,
+	AssignedEvaluators:       0,
+	AssignmentTimeoutMinutes: 1440,
+	BlindEvaluation:          false,
+	GraderVoteWeight:         0,
+	GraderPassPercent:        100,
+	SimilarityThreshold:      0,
+	AppealAfterMinutes:       10080,
+	AppealPanelSize:          3,
+	AppealPanelRepuPercent:   150,
+	RewardRepu:               0,
+	PenaltyRepu:              0,
+	AccuracyTracking:         false,
+	AccuracyWeighting:        false,
+	CalibrationVotes:         10,
+}
+
+// GetConfig reads the config from the world state, the defaults if no config was set
+func (ctx *TransactionContext) GetConfig() (*Config, error) {
+	config := defaultConfig
+	err := common.ReadConfig(ctx, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
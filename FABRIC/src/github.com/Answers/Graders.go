@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Graders - the identities running automated test suites off-chain, a grader registers its own enrollment identity,
+// an admin approves it, and its signed test results count as a weighted vote alongside the evaluators' thumbs up
+// ============================================================================================================================
+
+// graderObjectType the graders are stored under a composite key so they can never collide with an answer
+const graderObjectType = "grader"
+
+// status of a grader, only an active grader submits results
+const (
+	GraderStatusPending = "PENDING"
+	GraderStatusActive  = "ACTIVE"
+	GraderStatusRevoked = "REVOKED"
+)
+
+// names of the events raised by the grader functions
+const (
+	EventGraderChanged = "GraderChanged"
+	EventAnswerGraded  = "AnswerGraded"
+)
+
+// Grader an identity allowed to submit test results once approved, bound to the certificate that registered it
+type Grader struct {
+	GraderID     string `json:"GraderID"`
+	EnrollmentID string `json:"EnrollmentID"`
+	MSPID        string `json:"MSPID"`
+	Status       string `json:"Status"`
+	RegisteredOn string `json:"RegisteredOn"`
+	ChangedOn    string `json:"ChangedOn,omitempty"`
+}
+
+// GraderResult the test results of a grader for an answer, Weight the votes it counted for towards acceptance
+type GraderResult struct {
+	GraderID   string `json:"GraderID"`
+	PassCount  int    `json:"PassCount"`
+	TotalCount int    `json:"TotalCount"`
+	ReportCID  string `json:"ReportCID"`
+	MSPID      string `json:"MSPID"`
+	Weight     int    `json:"Weight"`
+	GradedOn   string `json:"GradedOn"`
+}
+
+// AnswerGradedEvent payload of AnswerGraded, raised by SubmitGraderResult
+type AnswerGradedEvent struct {
+	AnswerHashID        string `json:"AnswerHashID"`
+	QuestionID          string `json:"QuestionID"`
+	GraderID            string `json:"GraderID"`
+	PassCount           int    `json:"PassCount"`
+	TotalCount          int    `json:"TotalCount"`
+	ReportCID           string `json:"ReportCID"`
+	Weight              int    `json:"Weight"`
+	AttainedGraderVotes int    `json:"AttainedGraderVotes"`
+	GradedOn            string `json:"GradedOn"`
+}
+
+// GraderIDRequest request object of the functions changing a single grader
+type GraderIDRequest struct {
+	GraderID string `json:"GraderID" validate:"maxlength=64,format=id"`
+}
+
+// SubmitGraderResultRequest request object of SubmitGraderResult, EvaluatorsChaincode is required while the accuracy or
+// reputation of evaluators is settled with the outcome
+type SubmitGraderResultRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" metadata:",optional" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	GraderID            string `json:"GraderID" validate:"maxlength=64,format=id"`
+	PassCount           int    `json:"PassCount" validate:"minimum=0,maximum=100000"`
+	TotalCount          int    `json:"TotalCount" validate:"minimum=1,maximum=100000"`
+	ReportCID           string `json:"ReportCID" validate:"maxlength=128,format=cid"`
+}
+
+// GetGrader reads a grader from the world state, nil if it does not exist
+func (ctx *TransactionContext) GetGrader(graderID string) (*Grader, error) {
+	graderKey, err := ctx.GetStub().CreateCompositeKey(graderObjectType, []string{graderID})
+	if err != nil {
+		return nil, common.InternalError(err, "unable to create the grader key for %s", graderID)
+	}
+	graderAsBytes, err := ctx.GetStub().GetState(graderKey)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding grader for - %s", graderID)
+	}
+	if graderAsBytes == nil {
+		return nil, nil
+	}
+
+	grader := Grader{}
+	err = json.Unmarshal(graderAsBytes, &grader)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall grader - %s", graderID)
+	}
+	return &grader, nil
+}
+
+// PutGrader writes a grader to the world state
+func (ctx *TransactionContext) PutGrader(grader *Grader) error {
+	graderKey, err := ctx.GetStub().CreateCompositeKey(graderObjectType, []string{grader.GraderID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the grader key for %s", grader.GraderID)
+	}
+	buff, err := json.Marshal(grader)
+	if err != nil {
+		return common.InternalError(err, "unable to convert grader object to json")
+	}
+	err = ctx.GetStub().PutState(graderKey, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write grader - %s", grader.GraderID)
+	}
+	return nil
+}
+
+// registerGrader registers the enrollment identity invoking the transaction as a pending grader
+func registerGrader(ctx TransactionContextInterface, req GraderIDRequest) error {
+	existing, err := ctx.GetGrader(req.GraderID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return common.NewError(common.ErrAlreadyExists, "This grader already exists - %s", req.GraderID)
+	}
+
+	enrollmentID, err := common.GetClientID(ctx)
+	if err != nil {
+		return err
+	}
+	mspID, err := common.GetClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	registeredOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+
+	grader := &Grader{req.GraderID, enrollmentID, mspID, GraderStatusPending, registeredOn, ""}
+	err = ctx.PutGrader(grader)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventGraderChanged, grader)
+	return nil
+}
+
+// changeGraderStatus admin only, approves a pending grader or revokes a grader
+func changeGraderStatus(ctx TransactionContextInterface, function string, req GraderIDRequest, status string) error {
+	err := common.AssertAdmin(ctx, function)
+	if err != nil {
+		return err
+	}
+
+	grader, err := ctx.GetGrader(req.GraderID)
+	if err != nil {
+		return err
+	}
+	if grader == nil {
+		return common.NewError(common.ErrNotFound, "Nil data for %s", req.GraderID)
+	}
+	if grader.Status == GraderStatusRevoked || (status == GraderStatusActive && grader.Status != GraderStatusPending) {
+		return common.NewError(common.ErrInvalidState, "grader %s is %s", req.GraderID, grader.Status)
+	}
+
+	grader.Status = status
+	grader.ChangedOn, err = ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	err = ctx.PutGrader(grader)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventGraderChanged, grader)
+	return nil
+}
+
+// getGraderById returns the grader stored against the id
+func getGraderById(ctx TransactionContextInterface, req GraderIDRequest) (*Grader, error) {
+	grader, err := ctx.GetGrader(req.GraderID)
+	if err != nil {
+		return nil, err
+	}
+	if grader == nil {
+		return nil, common.NewError(common.ErrNotFound, "Nil data for %s", req.GraderID)
+	}
+	return grader, nil
+}
+
+// submitGraderResult records the test results of an active grader for the answer, only the identity that registered
+// the grader can submit them so the results are signed by it, a passing result counts as GraderVoteWeight votes
+func submitGraderResult(ctx TransactionContextInterface, req SubmitGraderResultRequest) error {
+	if req.PassCount > req.TotalCount {
+		return common.ValidationError("SubmitGraderResult", []common.FieldError{{Field: "PassCount", Message: "must not be more than TotalCount"}})
+	}
+
+	grader, err := ctx.GetGrader(req.GraderID)
+	if err != nil {
+		return err
+	}
+	if grader == nil {
+		return common.NewError(common.ErrNotFound, "Nil data for %s", req.GraderID)
+	}
+	if grader.Status != GraderStatusActive {
+		return common.NewError(common.ErrForbidden, "grader %s is %s", req.GraderID, grader.Status)
+	}
+	enrollmentID, err := common.GetClientID(ctx)
+	if err != nil {
+		return err
+	}
+	mspID, err := common.GetClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if enrollmentID != grader.EnrollmentID || mspID != grader.MSPID {
+		return common.NewError(common.ErrUnauthorized, "not authorized to submit results as grader %s", req.GraderID)
+	}
+
+	dat, err := getAnswerLedgerState(ctx, req.AnswerHashID)
+	if err != nil {
+		return err
+	}
+	dat, err = withAuthor(ctx, dat)
+	if err != nil {
+		return err
+	}
+	for _, result := range dat.GraderResults {
+		if result.GraderID == req.GraderID {
+			return common.NewError(common.ErrAlreadyExists, "grader %s already graded the answer %s", req.GraderID, req.AnswerHashID)
+		}
+	}
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, dat.QuestionID)
+	if err != nil {
+		return err
+	}
+
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return err
+	}
+	gradedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	weight := 0
+	if req.PassCount*100 >= req.TotalCount*config.GraderPassPercent {
+		weight = config.GraderVoteWeight
+	}
+	dat.GraderResults = append(dat.GraderResults, GraderResult{req.GraderID, req.PassCount, req.TotalCount, req.ReportCID, mspID, weight, gradedOn})
+	dat.AttainedGraderVotes = dat.AttainedGraderVotes + weight
+
+	accepted, err := acceptIfAttained(ctx, dat, questionData, gradedOn)
+	if err != nil {
+		return err
+	}
+	if accepted {
+		policy, err := findRepuPolicy(ctx, questionData.QuestionTech)
+		if err != nil {
+			return err
+		}
+		err = settleVotes(ctx, "SubmitGraderResult", req.EvaluatorsChaincode, dat, questionData, policy.RewardRepu, "")
+		if err != nil {
+			return err
+		}
+	}
+	err = ctx.PutAnswer(dat)
+	if err != nil {
+		return err
+	}
+	if accepted {
+		err = ctx.PutAcceptedAnswer(dat)
+		if err != nil {
+			return err
+		}
+	}
+	ctx.EmitEvent(EventAnswerGraded, AnswerGradedEvent{req.AnswerHashID, dat.QuestionID, req.GraderID, req.PassCount, req.TotalCount, req.ReportCID, weight,
+		dat.AttainedGraderVotes, gradedOn})
+	if accepted {
+		ctx.EmitEvent(EventAnswerAccepted, AnswerAcceptedEvent{req.AnswerHashID, dat.QuestionID, dat.AnsweredBy, dat.AttainedEvaluatorThumbsUp, dat.AttainedGraderVotes, gradedOn})
+	}
+
+	fmt.Printf("- end submitGraderResult %s for %s\n", req.GraderID, req.AnswerHashID)
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Plagiarism - an answer byte-identical to the answer of another student, to the same or any question, is stored under
+// an answer hash id of its own and both answers are flagged, every flagged pair is indexed for GetFlaggedAnswerPairs
+// ============================================================================================================================
+
+// contentObjectType the copies of an answer are indexed under a composite key of the content hash id, the answer
+// first submitted with the content is stored against the content hash id itself
+const contentObjectType = "content"
+
+// plagiarismObjectType the flagged pairs are indexed under a composite key of the two answers
+const plagiarismObjectType = "plagiarism"
+
+// EventAnswerFlagged is raised by SubmitAnswer and RevealAnswer for a copy, its payload is an AnswerFlaggedEvent
+const EventAnswerFlagged = "AnswerFlagged"
+
+// PlagiarismFlag the answer is byte-identical to the matched answer of another student
+type PlagiarismFlag struct {
+	MatchedAnswerHashID string `json:"MatchedAnswerHashID"`
+	FlaggedOn           string `json:"FlaggedOn"`
+}
+
+// PlagiarismPair two byte-identical answers of different students, AnswerHashID was submitted first
+type PlagiarismPair struct {
+	AnswerHashID        string `json:"AnswerHashID"`
+	QuestionID          string `json:"QuestionID"`
+	MatchedAnswerHashID string `json:"MatchedAnswerHashID"`
+	MatchedQuestionID   string `json:"MatchedQuestionID"`
+	ContentHashID       string `json:"ContentHashID"`
+	FlaggedOn           string `json:"FlaggedOn"`
+}
+
+// AnswerFlaggedEvent payload of AnswerFlagged, MatchedAnswerHashIDs are the earlier answers with the same content
+type AnswerFlaggedEvent struct {
+	AnswerHashID         string   `json:"AnswerHashID"`
+	ContentHashID        string   `json:"ContentHashID"`
+	MatchedAnswerHashIDs []string `json:"MatchedAnswerHashIDs"`
+	FlaggedOn            string   `json:"FlaggedOn"`
+}
+
+// FlaggedAnswerPairsRequest request object of GetFlaggedAnswerPairsForQuestion, the pairs with an answer to QuestionID
+type FlaggedAnswerPairsRequest struct {
+	QuestionID string `json:"QuestionID" validate:"format=hash"`
+}
+
+// PutContentCopy indexes the copy against the content hash id
+func (ctx *TransactionContext) PutContentCopy(contentHashID string, answerHashID string) error {
+	contentKey, err := ctx.GetStub().CreateCompositeKey(contentObjectType, []string{contentHashID, answerHashID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the content key for %s", answerHashID)
+	}
+	err = ctx.GetStub().PutState(contentKey, []byte(answerHashID))
+	if err != nil {
+		return common.InternalError(err, "unable to index the content of - %s", answerHashID)
+	}
+	return nil
+}
+
+// GetContentCopies the hash ids of the copies indexed against the content hash id
+func (ctx *TransactionContext) GetContentCopies(contentHashID string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(contentObjectType, []string{contentHashID})
+	if err != nil {
+		return nil, common.InternalError(err, "unable to read the copies of %s", contentHashID)
+	}
+	defer resultsIterator.Close()
+
+	var copies []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the copies of %s", contentHashID)
+		}
+		copies = append(copies, string(queryResponse.Value))
+	}
+	return copies, nil
+}
+
+// PutPlagiarismPair indexes the flagged pair against its two answers
+func (ctx *TransactionContext) PutPlagiarismPair(pair *PlagiarismPair) error {
+	pairKey, err := ctx.GetStub().CreateCompositeKey(plagiarismObjectType, []string{pair.AnswerHashID, pair.MatchedAnswerHashID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the plagiarism key for %s", pair.MatchedAnswerHashID)
+	}
+	buff, err := json.Marshal(pair)
+	if err != nil {
+		return common.InternalError(err, "unable to convert the plagiarism pair to json")
+	}
+	err = ctx.GetStub().PutState(pairKey, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to index the plagiarism of - %s", pair.MatchedAnswerHashID)
+	}
+	return nil
+}
+
+// findContentMatches the answers submitted with the content hash id, with their authors so they can be told apart
+func findContentMatches(ctx TransactionContextInterface, contentHashID string) ([]*Answer, error) {
+	answerHashIDs, err := ctx.GetContentCopies(contentHashID)
+	if err != nil {
+		return nil, err
+	}
+	answerHashIDs = append([]string{contentHashID}, answerHashIDs...)
+
+	var matches []*Answer
+	for _, answerHashID := range answerHashIDs {
+		match, err := ctx.GetAnswer(answerHashID)
+		if err != nil {
+			return nil, err
+		}
+		if match == nil {
+			continue
+		}
+		match, err = withAuthor(ctx, match)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// copyHashID the answer hash id of a copy, unique to the transaction so it does not give away the student
+func copyHashID(txID string, contentHashID string) string {
+	digest := sha256.Sum256([]byte(contentHashID + ":" + txID))
+	return hex.EncodeToString(digest[:])
+}
+
+// flagCopies flags the answer and every earlier answer with the same content as a pair, it leaves writing the answer
+// and raising the returned event to the caller
+func flagCopies(ctx TransactionContextInterface, answer *Answer, matches []*Answer) (*AnswerFlaggedEvent, error) {
+	event := &AnswerFlaggedEvent{answer.AnswerHashID, answer.ContentHashID, []string{}, answer.AnsweredOn}
+	for _, match := range matches {
+		match.PlagiarismFlags = append(match.PlagiarismFlags, PlagiarismFlag{answer.AnswerHashID, answer.AnsweredOn})
+		err := ctx.PutAnswer(match)
+		if err != nil {
+			return nil, err
+		}
+		answer.PlagiarismFlags = append(answer.PlagiarismFlags, PlagiarismFlag{match.AnswerHashID, answer.AnsweredOn})
+
+		err = ctx.PutPlagiarismPair(&PlagiarismPair{match.AnswerHashID, match.QuestionID, answer.AnswerHashID, answer.QuestionID, answer.ContentHashID, answer.AnsweredOn})
+		if err != nil {
+			return nil, err
+		}
+		event.MatchedAnswerHashIDs = append(event.MatchedAnswerHashIDs, match.AnswerHashID)
+	}
+
+	err := ctx.PutContentCopy(answer.ContentHashID, answer.AnswerHashID)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// getFlaggedAnswerPairs every flagged pair, or those with an answer to the question when questionID is given, ordered by
+// the time they were flagged
+func getFlaggedAnswerPairs(ctx TransactionContextInterface, questionID string) ([]*PlagiarismPair, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(plagiarismObjectType, []string{})
+	if err != nil {
+		return nil, common.InternalError(err, "unable to read the flagged answers")
+	}
+	defer resultsIterator.Close()
+
+	pairs := []*PlagiarismPair{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the flagged answers")
+		}
+		pair := PlagiarismPair{}
+		err = json.Unmarshal(queryResponse.Value, &pair)
+		if err != nil {
+			return nil, common.InternalError(err, "unable to unmarshall the flagged pair - %s", queryResponse.Key)
+		}
+		if questionID != "" && pair.QuestionID != questionID && pair.MatchedQuestionID != questionID {
+			continue
+		}
+		pairs = append(pairs, &pair)
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].FlaggedOn < pairs[j].FlaggedOn })
+	return pairs, nil
+}
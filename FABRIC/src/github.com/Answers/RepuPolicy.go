@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Reputation Policy - the evaluators that gave an answer a thumbs up gain RewardRepu once the answer is accepted and lose
+// PenaltyRepu once an appeal upholds its rejection, in the tech that qualified them, an admin sets the amounts per tech
+// of the question and the techs without a policy of their own take the RewardRepu and PenaltyRepu of the config
+// ============================================================================================================================
+
+// repuPolicyObjectType the policies are stored under a composite key of the canonical tech id
+const repuPolicyObjectType = "repupolicy"
+
+// EventTechRepuPolicyChanged is raised by SetTechRepuPolicy, its payload is the TechRepuPolicy
+const EventTechRepuPolicyChanged = "TechRepuPolicyChanged"
+
+// TechRepuPolicy the reputation the evaluators of an answer to a question of the tech gain and lose with its outcome
+type TechRepuPolicy struct {
+	TechID      string `json:"TechID"`
+	RewardRepu  int    `json:"RewardRepu"`
+	PenaltyRepu int    `json:"PenaltyRepu"`
+	ChangedOn   string `json:"ChangedOn,omitempty"`
+}
+
+// SetTechRepuPolicyRequest request object of SetTechRepuPolicy
+type SetTechRepuPolicyRequest struct {
+	TechsChaincode string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+	TechName       string `json:"TechName" validate:"maxlength=64,format=tech"`
+	RewardRepu     int    `json:"RewardRepu" validate:"minimum=0,maximum=1000"`
+	PenaltyRepu    int    `json:"PenaltyRepu" validate:"minimum=0,maximum=1000"`
+}
+
+// TechRepuPolicyRequest request object of GetTechRepuPolicy
+type TechRepuPolicyRequest struct {
+	TechsChaincode string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+	TechName       string `json:"TechName" validate:"maxlength=64,format=tech"`
+}
+
+// GetTechRepuPolicy reads the policy an admin set for the tech, nil if it has none
+func (ctx *TransactionContext) GetTechRepuPolicy(techID string) (*TechRepuPolicy, error) {
+	policyKey, err := ctx.GetStub().CreateCompositeKey(repuPolicyObjectType, []string{techID})
+	if err != nil {
+		return nil, common.InternalError(err, "unable to create the policy key for %s", techID)
+	}
+	policyAsBytes, err := ctx.GetStub().GetState(policyKey)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding the policy of - %s", techID)
+	}
+	if policyAsBytes == nil {
+		return nil, nil
+	}
+
+	policy := TechRepuPolicy{}
+	err = json.Unmarshal(policyAsBytes, &policy)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall the policy of %s", techID)
+	}
+	return &policy, nil
+}
+
+// PutTechRepuPolicy writes the policy of the tech, replacing an earlier one
+func (ctx *TransactionContext) PutTechRepuPolicy(policy *TechRepuPolicy) error {
+	policyKey, err := ctx.GetStub().CreateCompositeKey(repuPolicyObjectType, []string{policy.TechID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the policy key for %s", policy.TechID)
+	}
+	buff, err := json.Marshal(policy)
+	if err != nil {
+		return common.InternalError(err, "unable to convert the policy to json")
+	}
+	err = ctx.GetStub().PutState(policyKey, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write the policy of - %s", policy.TechID)
+	}
+	return nil
+}
+
+// setTechRepuPolicy admin only, sets the reward and penalty of the evaluators of answers to questions of the tech
+func setTechRepuPolicy(ctx TransactionContextInterface, req SetTechRepuPolicyRequest) error {
+	err := common.AssertAdmin(ctx, "SetTechRepuPolicy")
+	if err != nil {
+		return err
+	}
+
+	tech, err := common.ResolveTech(ctx, req.TechsChaincode, req.TechName)
+	if err != nil {
+		return err
+	}
+	changedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	policy := &TechRepuPolicy{tech.TechID, req.RewardRepu, req.PenaltyRepu, changedOn}
+	err = ctx.PutTechRepuPolicy(policy)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventTechRepuPolicyChanged, policy)
+	return nil
+}
+
+// getTechRepuPolicy the policy in use for the tech, the one of the config when an admin set none for it
+func getTechRepuPolicy(ctx TransactionContextInterface, req TechRepuPolicyRequest) (*TechRepuPolicy, error) {
+	tech, err := common.ResolveTech(ctx, req.TechsChaincode, req.TechName)
+	if err != nil {
+		return nil, err
+	}
+	return findRepuPolicy(ctx, tech.TechID)
+}
+
+// findRepuPolicy the policy in use for the tech of a question, the one of the config when an admin set none for it
+// or the question has a free text tech from before the tech registry
+func findRepuPolicy(ctx TransactionContextInterface, techID string) (*TechRepuPolicy, error) {
+	policy, err := ctx.GetTechRepuPolicy(techID)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		return policy, nil
+	}
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &TechRepuPolicy{TechID: techID, RewardRepu: config.RewardRepu, PenaltyRepu: config.PenaltyRepu}, nil
+}
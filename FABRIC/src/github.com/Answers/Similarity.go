@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Similarity - a plagiarism checker run off-chain attests how similar two answers are, the attestation is linked to
+// both answers and an answer of a score at or above SimilarityThreshold is disputed until an admin resolves it
+// ============================================================================================================================
+
+// CheckerAttribute is the enrollment certificate attribute of the plagiarism checkers allowed to attest similarity,
+// register a checker with fabric-ca-client register --id.attrs 'qna.checker=true:ecert'
+const CheckerAttribute = "qna.checker"
+
+// similarityObjectType an attestation is stored under a composite key of both answers in either order, so the
+// attestations of an answer are read by its hash id alone
+const similarityObjectType = "similarity"
+
+// outcome of a resolved dispute
+const (
+	DisputeOutcomeCleared  = "CLEARED"
+	DisputeOutcomeRejected = "REJECTED"
+)
+
+// names of the events raised by the similarity functions
+const (
+	EventSimilarityAttested = "SimilarityAttested"
+	EventAnswerDisputed     = "AnswerDisputed"
+	EventDisputeResolved    = "DisputeResolved"
+)
+
+// SimilarityAttestation the similarity of two answers in percent as attested by a checker
+type SimilarityAttestation struct {
+	AnswerHashID        string `json:"AnswerHashID"`
+	AnswerCID           string `json:"AnswerCID"`
+	MatchedAnswerHashID string `json:"MatchedAnswerHashID"`
+	MatchedAnswerCID    string `json:"MatchedAnswerCID"`
+	Score               int    `json:"Score"`
+	ReportCID           string `json:"ReportCID,omitempty"`
+	CheckerID           string `json:"CheckerID"`
+	MSPID               string `json:"MSPID"`
+	AttestedOn          string `json:"AttestedOn"`
+}
+
+// AnswerDisputedEvent payload of AnswerDisputed, raised by AttestSimilarity for every answer it disputes
+type AnswerDisputedEvent struct {
+	AnswerHashID        string `json:"AnswerHashID"`
+	MatchedAnswerHashID string `json:"MatchedAnswerHashID"`
+	Score               int    `json:"Score"`
+	DisputedOn          string `json:"DisputedOn"`
+}
+
+// DisputeResolvedEvent payload of DisputeResolved, raised by ResolveDispute
+type DisputeResolvedEvent struct {
+	AnswerHashID string `json:"AnswerHashID"`
+	Outcome      string `json:"Outcome"`
+	Status       string `json:"Status"`
+	ReasonCID    string `json:"ReasonCID,omitempty"`
+	ResolvedOn   string `json:"ResolvedOn"`
+}
+
+// AttestSimilarityRequest request object of AttestSimilarity, Score is in percent
+type AttestSimilarityRequest struct {
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	MatchedAnswerHashID string `json:"MatchedAnswerHashID" validate:"format=hash"`
+	Score               int    `json:"Score" validate:"minimum=0,maximum=100"`
+	ReportCID           string `json:"ReportCID" metadata:",optional" validate:"maxlength=128,format=cid"`
+}
+
+// ResolveDisputeRequest request object of ResolveDispute, EvaluatorsChaincode is required while the accuracy or
+// reputation of evaluators is settled with the outcome
+type ResolveDisputeRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" metadata:",optional" validate:"maxlength=64,format=chaincode"`
+	AnswerHashID        string `json:"AnswerHashID" validate:"format=hash"`
+	Outcome             string `json:"Outcome" validate:"maxlength=16"`
+	ReasonCID           string `json:"ReasonCID" metadata:",optional" validate:"maxlength=128,format=cid"`
+}
+
+// PutSimilarityAttestation links the attestation to both of its answers, replacing an earlier one of the same pair
+func (ctx *TransactionContext) PutSimilarityAttestation(attestation *SimilarityAttestation) error {
+	buff, err := json.Marshal(attestation)
+	if err != nil {
+		return common.InternalError(err, "unable to convert the attestation to json")
+	}
+	pairs := [][]string{{attestation.AnswerHashID, attestation.MatchedAnswerHashID}, {attestation.MatchedAnswerHashID, attestation.AnswerHashID}}
+	for _, pair := range pairs {
+		similarityKey, err := ctx.GetStub().CreateCompositeKey(similarityObjectType, pair)
+		if err != nil {
+			return common.InternalError(err, "unable to create the similarity key for %s", pair[0])
+		}
+		err = ctx.GetStub().PutState(similarityKey, buff)
+		if err != nil {
+			return common.InternalError(err, "unable to write the similarity of - %s", pair[0])
+		}
+	}
+	return nil
+}
+
+// GetSimilarityAttestations the attestations linked to the answer
+func (ctx *TransactionContext) GetSimilarityAttestations(answerHashID string) ([]*SimilarityAttestation, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(similarityObjectType, []string{answerHashID})
+	if err != nil {
+		return nil, common.InternalError(err, "unable to read the similarity of %s", answerHashID)
+	}
+	defer resultsIterator.Close()
+
+	attestations := []*SimilarityAttestation{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the similarity of %s", answerHashID)
+		}
+		attestation := SimilarityAttestation{}
+		err = json.Unmarshal(queryResponse.Value, &attestation)
+		if err != nil {
+			return nil, common.InternalError(err, "unable to unmarshall the attestation - %s", queryResponse.Key)
+		}
+		attestations = append(attestations, &attestation)
+	}
+	return attestations, nil
+}
+
+// assertChecker fails with FORBIDDEN unless the client invoking the transaction is a plagiarism checker
+func assertChecker(ctx TransactionContextInterface, function string) error {
+	err := ctx.GetClientIdentity().AssertAttributeValue(CheckerAttribute, "true")
+	if err != nil {
+		return common.NewError(common.ErrForbidden, "%s can only be called by a plagiarism checker - %s", function, err.Error())
+	}
+	return nil
+}
+
+// isOpenForEvaluation whether the answer still waits for its outcome, answers stored before the status was introduced
+// have none and are pending
+func isOpenForEvaluation(answer *Answer) bool {
+	return answer.Status == "" || answer.Status == AnswerStatusPending
+}
+
+// attestSimilarity records the similarity of two answers and disputes those still waiting for their outcome when the
+// score reaches the configured threshold, two answers of the same student are treated alike so the checker cannot tell
+// the authors of blind answers apart, resolveDispute only clears them
+func attestSimilarity(ctx TransactionContextInterface, req AttestSimilarityRequest) error {
+	if req.AnswerHashID == req.MatchedAnswerHashID {
+		return common.ValidationError("AttestSimilarity", []common.FieldError{{Field: "MatchedAnswerHashID", Message: "must not be AnswerHashID"}})
+	}
+	err := assertChecker(ctx, "AttestSimilarity")
+	if err != nil {
+		return err
+	}
+
+	var answers []*Answer
+	for _, answerHashID := range []string{req.AnswerHashID, req.MatchedAnswerHashID} {
+		dat, err := getAnswerLedgerState(ctx, answerHashID)
+		if err != nil {
+			return err
+		}
+		dat, err = withAuthor(ctx, dat)
+		if err != nil {
+			return err
+		}
+		answers = append(answers, dat)
+	}
+
+	checkerID, err := common.GetClientID(ctx)
+	if err != nil {
+		return err
+	}
+	mspID, err := common.GetClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	attestedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	attestation := &SimilarityAttestation{req.AnswerHashID, answers[0].AnswerCID, req.MatchedAnswerHashID, answers[1].AnswerCID, req.Score, req.ReportCID,
+		checkerID, mspID, attestedOn}
+	err = ctx.PutSimilarityAttestation(attestation)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventSimilarityAttested, attestation)
+
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return err
+	}
+	if config.SimilarityThreshold == 0 || req.Score < config.SimilarityThreshold {
+		return nil
+	}
+	for i, dat := range answers {
+		if !isOpenForEvaluation(dat) {
+			continue
+		}
+		dat.Status = AnswerStatusDisputed
+		dat.DisputedOn = attestedOn
+		err = ctx.PutAnswer(dat)
+		if err != nil {
+			return err
+		}
+		ctx.EmitEvent(EventAnswerDisputed, AnswerDisputedEvent{dat.AnswerHashID, answers[1-i].AnswerHashID, req.Score, attestedOn})
+	}
+	return nil
+}
+
+// getSimilarityAttestations the attestations linked to the answer
+func getSimilarityAttestations(ctx TransactionContextInterface, req AnswerIDRequest) ([]*SimilarityAttestation, error) {
+	return ctx.GetSimilarityAttestations(req.AnswerHashID)
+}
+
+// resolveDispute admin only, a cleared answer goes back to pending and is accepted if it already attained its votes,
+// a rejected answer is final and its author is revealed
+func resolveDispute(ctx TransactionContextInterface, req ResolveDisputeRequest) error {
+	if req.Outcome != DisputeOutcomeCleared && req.Outcome != DisputeOutcomeRejected {
+		return common.ValidationError("ResolveDispute", []common.FieldError{{Field: "Outcome", Message: "must be CLEARED or REJECTED"}})
+	}
+	err := common.AssertAdmin(ctx, "ResolveDispute")
+	if err != nil {
+		return err
+	}
+
+	dat, err := getAnswerLedgerState(ctx, req.AnswerHashID)
+	if err != nil {
+		return err
+	}
+	if dat.Status != AnswerStatusDisputed {
+		return common.NewError(common.ErrInvalidState, "the answer %s is not disputed", req.AnswerHashID)
+	}
+	dat, err = withAuthor(ctx, dat)
+	if err != nil {
+		return err
+	}
+	if req.Outcome == DisputeOutcomeRejected {
+		similarToOthers, err := isSimilarToOthers(ctx, dat)
+		if err != nil {
+			return err
+		}
+		if !similarToOthers {
+			return common.NewError(common.ErrInvalidState, "the answer %s is only attested similar to answers of its own student and can only be CLEARED", req.AnswerHashID)
+		}
+	}
+	resolvedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+
+	questionData, err := getQuestionFromChaincode(ctx, req.QuestionsChaincode, dat.QuestionID)
+	if err != nil {
+		return err
+	}
+	accepted := false
+	reward := 0
+	if req.Outcome == DisputeOutcomeRejected {
+		err = rejectAnswer(ctx, dat, resolvedOn)
+		if err != nil {
+			return err
+		}
+	} else {
+		dat.Status = AnswerStatusPending
+		accepted, err = acceptIfAttained(ctx, dat, questionData, resolvedOn)
+		if err != nil {
+			return err
+		}
+		if accepted {
+			policy, err := findRepuPolicy(ctx, questionData.QuestionTech)
+			if err != nil {
+				return err
+			}
+			reward = policy.RewardRepu
+		}
+	}
+	err = settleVotes(ctx, "ResolveDispute", req.EvaluatorsChaincode, dat, questionData, reward, "")
+	if err != nil {
+		return err
+	}
+
+	err = ctx.PutAnswer(dat)
+	if err != nil {
+		return err
+	}
+	if accepted {
+		err = ctx.PutAcceptedAnswer(dat)
+		if err != nil {
+			return err
+		}
+	}
+	ctx.EmitEvent(EventDisputeResolved, DisputeResolvedEvent{req.AnswerHashID, req.Outcome, dat.Status, req.ReasonCID, resolvedOn})
+	if accepted {
+		ctx.EmitEvent(EventAnswerAccepted, AnswerAcceptedEvent{req.AnswerHashID, dat.QuestionID, dat.AnsweredBy, dat.AttainedEvaluatorThumbsUp, dat.AttainedGraderVotes, resolvedOn})
+	}
+	return nil
+}
+
+// isSimilarToOthers whether an attestation links the answer to an answer of another student, the answer must have been
+// read through withAuthor
+func isSimilarToOthers(ctx TransactionContextInterface, answer *Answer) (bool, error) {
+	attestations, err := ctx.GetSimilarityAttestations(answer.AnswerHashID)
+	if err != nil {
+		return false, err
+	}
+	for _, attestation := range attestations {
+		matchedAnswerHashID := attestation.MatchedAnswerHashID
+		if matchedAnswerHashID == answer.AnswerHashID {
+			matchedAnswerHashID = attestation.AnswerHashID
+		}
+		matched, err := getAnswerLedgerState(ctx, matchedAnswerHashID)
+		if err != nil {
+			return false, err
+		}
+		matched, err = withAuthor(ctx, matched)
+		if err != nil {
+			return false, err
+		}
+		if matched.AnsweredBy != answer.AnsweredBy {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rejectAnswer makes the outcome of the answer final without accepting it, the answer must have been read through
+// withAuthor and the caller writes it
+func rejectAnswer(ctx TransactionContextInterface, answer *Answer, now string) error {
+	answer.Status = AnswerStatusRejected
+	answer.RejectedOn = now
+	return revealAuthor(ctx, answer, now)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Evaluator Work Queue - the answers an evaluator can give a thumbs up to now, oldest first, so evaluators do not need
+// to know the answer hash ids in advance
+// ============================================================================================================================
+
+// page size of GetPendingEvaluationsForEvaluator, the maximum is declared by the tag of PendingEvaluationsRequest.PageSize
+const (
+	DefaultPendingPageSize = 20
+	MaxPendingPageSize     = 100
+)
+
+// PendingEvaluationsRequest request object of GetPendingEvaluationsForEvaluator, Bookmark is the one returned with
+// the previous page
+type PendingEvaluationsRequest struct {
+	QuestionsChaincode  string `json:"QuestionsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorsChaincode string `json:"EvaluatorsChaincode" validate:"maxlength=64,format=chaincode"`
+	TechsChaincode      string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+	EvaluatorID         string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	PageSize            int    `json:"PageSize" metadata:",optional" validate:"minimum=1,maximum=100"`
+	Bookmark            string `json:"Bookmark" metadata:",optional" validate:"format=hash"`
+}
+
+// PendingEvaluationsPage one page of the work queue, Bookmark is empty on the last page
+type PendingEvaluationsPage struct {
+	Answers  []*Answer `json:"Answers"`
+	Bookmark string    `json:"Bookmark"`
+}
+
+// getPendingEvaluationsForEvaluator the answers still waiting for their outcome that the evaluator has not evaluated, has no conflict
+// with and holds the reputation for, ordered by the time they were answered and paged after the bookmarked answer
+func getPendingEvaluationsForEvaluator(ctx TransactionContextInterface, req PendingEvaluationsRequest) (*PendingEvaluationsPage, error) {
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPendingPageSize
+	}
+
+	evaluator, err := getEvaluatorFromChaincode(ctx, req.EvaluatorsChaincode, req.EvaluatorID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmark *Answer
+	if req.Bookmark != "" {
+		bookmark, err = ctx.GetAnswer(req.Bookmark)
+		if err != nil {
+			return nil, err
+		}
+		if bookmark == nil {
+			return nil, common.ValidationError("GetPendingEvaluationsForEvaluator", []common.FieldError{{Field: "Bookmark", Message: "is not an answer"}})
+		}
+	}
+
+	// the range of simple keys holds the answers, the config and the accepted index are composite keys outside of it
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, common.InternalError(err, "range query failed")
+	}
+	defer resultsIterator.Close()
+
+	var pending []*Answer
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the query result")
+		}
+		// the InitLedger self-test value is not an answer
+		ans, err := JSONtoAns(queryResponse.Value)
+		if err != nil || ans.AnswerHashID != queryResponse.Key {
+			continue
+		}
+		if bookmark != nil && !answeredBefore(bookmark, &ans) {
+			continue
+		}
+		if !isOpenForEvaluation(&ans) || stringInSlice(ans.AnswerHashID, evaluator.EvaluatedAnswers) ||
+			stringInSlice(evaluator.EvaluatorID, ans.EvaluatedBy) || !isAwaitingAssignment(&ans, evaluator.EvaluatorID, now) {
+			continue
+		}
+		pending = append(pending, &ans)
+	}
+	sort.Slice(pending, func(i, j int) bool { return answeredBefore(pending[i], pending[j]) })
+
+	// the questions and tech ancestors are read once for all the answers to the same question or tech
+	questions := map[string]Question{}
+	ancestorsByTech := map[string][]string{}
+	page := &PendingEvaluationsPage{Answers: []*Answer{}}
+	for _, ans := range pending {
+		if len(page.Answers) == pageSize {
+			page.Bookmark = page.Answers[pageSize-1].AnswerHashID
+			break
+		}
+		question, found := questions[ans.QuestionID]
+		if !found {
+			question, err = getQuestionFromChaincode(ctx, req.QuestionsChaincode, ans.QuestionID)
+			if err != nil {
+				return nil, err
+			}
+			questions[ans.QuestionID] = question
+		}
+		ancestors, found := ancestorsByTech[question.QuestionTech]
+		if !found {
+			ancestors, err = getTechAncestors(ctx, req.TechsChaincode, question.QuestionTech)
+			if err != nil {
+				return nil, err
+			}
+			ancestorsByTech[question.QuestionTech] = ancestors
+		}
+
+		// the answers are returned as stored, the author of a blind answer is only read for the conflict check
+		authored, err := withAuthor(ctx, ans)
+		if err != nil {
+			return nil, err
+		}
+		_, err = checkEvaluatorEligible(evaluator, "", question, authored, ancestors, config.ParentRepuPercent)
+		if err == nil {
+			page.Answers = append(page.Answers, ans)
+		}
+	}
+
+	fmt.Printf("- getPendingEvaluationsForEvaluator found %d answers for %s\n", len(page.Answers), req.EvaluatorID)
+	return page, nil
+}
+
+// answeredBefore orders answers by the time they were answered, then by hash id for answers of the same second
+func answeredBefore(a *Answer, b *Answer) bool {
+	if a.AnsweredOn != b.AnsweredOn {
+		return a.AnsweredOn < b.AnsweredOn
+	}
+	return a.AnswerHashID < b.AnswerHashID
+}
+
+// isAwaitingAssignment whether the evaluator may evaluate the answer as far as assignment goes, answers that only take
+// assigned evaluators wait for the evaluators whose assignment is pending and not past due
+func isAwaitingAssignment(answer *Answer, evaluatorID string, now string) bool {
+	if !answer.AssignedEvaluatorsOnly {
+		return true
+	}
+	for _, assignment := range answer.AssignedEvaluators {
+		if assignment.EvaluatorID == evaluatorID && assignment.Status == AssignmentStatusPending && now <= assignment.DueOn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// ============================================================================================================================
+// Access Control - admin functions are restricted to clients enrolled with the admin attribute, the functions other
+// chaincodes call to settle reputation to the trusted chaincodes of the config
+// ============================================================================================================================
+
+// AdminAttribute is the enrollment certificate attribute of the clients allowed to call admin functions,
+// register an admin with fabric-ca-client register --id.attrs 'qna.admin=true:ecert'
+const AdminAttribute = "qna.admin"
+
+// AssertAdmin fails with FORBIDDEN unless the client invoking the transaction is an admin
+func AssertAdmin(ctx TransactionContextInterface, function string) error {
+	err := ctx.GetClientIdentity().AssertAttributeValue(AdminAttribute, "true")
+	if err != nil {
+		return NewError(ErrForbidden, "%s can only be called by an admin - %s", function, err.Error())
+	}
+	return nil
+}
+
+// AssertTrustedCaller fails with FORBIDDEN unless the client invoking the transaction is an admin or the transaction was
+// proposed to one of the TrustedChaincodes of the account config, which calls the function through InvokeChaincode, a
+// client proposing the function to this chaincode directly is neither
+func AssertTrustedCaller(ctx TransactionContextInterface, function string) error {
+	if ctx.GetClientIdentity().AssertAttributeValue(AdminAttribute, "true") == nil {
+		return nil
+	}
+	config, err := GetAccountConfig(ctx)
+	if err != nil {
+		return err
+	}
+	proposedTo, err := GetProposedChaincode(ctx)
+	if err != nil {
+		return err
+	}
+	if !stringInSlice(proposedTo, config.TrustedChaincodes) {
+		return NewError(ErrForbidden, "%s can only be called by an admin or through the chaincodes [%s], not %s", function, strings.Join(config.TrustedChaincodes, ", "), proposedTo)
+	}
+	return nil
+}
+
+// GetProposedChaincode the name of the chaincode the client proposed the transaction to, the chaincode a chaincode
+// called through InvokeChaincode was called from at the start of the chain, read from the signed proposal
+func GetProposedChaincode(ctx TransactionContextInterface) (string, error) {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return "", InternalError(err, "unable to read the signed proposal")
+	}
+	if signedProposal == nil {
+		return "", NewError(ErrInternal, "the transaction has no signed proposal")
+	}
+	proposal := &pb.Proposal{}
+	err = proto.Unmarshal(signedProposal.ProposalBytes, proposal)
+	if err != nil {
+		return "", InternalError(err, "unable to unmarshall the proposal")
+	}
+	header := &cb.Header{}
+	err = proto.Unmarshal(proposal.Header, header)
+	if err != nil {
+		return "", InternalError(err, "unable to unmarshall the proposal header")
+	}
+	channelHeader := &cb.ChannelHeader{}
+	err = proto.Unmarshal(header.ChannelHeader, channelHeader)
+	if err != nil {
+		return "", InternalError(err, "unable to unmarshall the channel header")
+	}
+	extension := &pb.ChaincodeHeaderExtension{}
+	err = proto.Unmarshal(channelHeader.Extension, extension)
+	if err != nil {
+		return "", InternalError(err, "unable to unmarshall the chaincode header extension")
+	}
+	return extension.GetChaincodeId().GetName(), nil
+}
+
+// GetClientID the unique id (subject and issuer of the certificate) of the client invoking the transaction
+func GetClientID(ctx TransactionContextInterface) (string, error) {
+	id, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", InternalError(err, "unable to read the client identity")
+	}
+	return id, nil
+}
+
+// GetClientMSPID the MSP id of the organization of the client invoking the transaction
+func GetClientMSPID(ctx TransactionContextInterface) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", InternalError(err, "unable to read the client msp id")
+	}
+	return mspID, nil
+}
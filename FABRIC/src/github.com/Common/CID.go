@@ -0,0 +1,167 @@
+package common
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ============================================================================================================================
+// IPFS CIDs - CIDv0 and CIDv1 are parsed down to their multihash so the ledger only references well formed content ids
+// ============================================================================================================================
+
+// multicodec content types a CID may address
+const (
+	CodecRaw     = 0x55
+	CodecDagPB   = 0x70
+	CodecDagCBOR = 0x71
+	CodecDagJSON = 0x0129
+)
+
+// multihash function codes
+const (
+	HashSHA2_256   = 0x12
+	HashSHA2_512   = 0x13
+	HashSHA3_512   = 0x14
+	HashSHA3_256   = 0x16
+	HashBlake2b256 = 0xb220
+)
+
+// digest length of every multihash function a CID may use, the identity hash is not accepted
+var multihashLengths = map[uint64]int{
+	HashSHA2_256:   32,
+	HashSHA2_512:   64,
+	HashSHA3_512:   64,
+	HashSHA3_256:   32,
+	HashBlake2b256: 32,
+}
+
+var knownCodecs = map[uint64]bool{CodecRaw: true, CodecDagPB: true, CodecDagCBOR: true, CodecDagJSON: true}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// CID a parsed IPFS content id
+type CID struct {
+	Version  uint64
+	Codec    uint64
+	HashCode uint64
+	Digest   []byte
+}
+
+// ParseCID parses a base58btc CIDv0 (Qm...) or a base32 CIDv1 (b...)
+func ParseCID(cid string) (CID, error) {
+	if len(cid) == 46 && strings.HasPrefix(cid, "Qm") {
+		multihash, err := decodeBase58(cid)
+		if err != nil {
+			return CID{}, err
+		}
+		hashCode, digest, err := parseMultihash(multihash)
+		if err != nil {
+			return CID{}, err
+		}
+		if hashCode != HashSHA2_256 {
+			return CID{}, errors.New("a CIDv0 must use a sha2-256 multihash")
+		}
+		return CID{0, CodecDagPB, hashCode, digest}, nil
+	}
+
+	if !strings.HasPrefix(cid, "b") {
+		return CID{}, errors.New("unsupported multibase, only base32 (b...) CIDv1 are accepted")
+	}
+	buff, err := base32Encoding.DecodeString(strings.ToUpper(cid[1:]))
+	if err != nil {
+		return CID{}, errors.New("invalid base32 encoding")
+	}
+
+	version, buff, err := readVarint(buff)
+	if err != nil {
+		return CID{}, err
+	}
+	if version != 1 {
+		return CID{}, fmt.Errorf("unsupported CID version %d", version)
+	}
+	codec, buff, err := readVarint(buff)
+	if err != nil {
+		return CID{}, err
+	}
+	if !knownCodecs[codec] {
+		return CID{}, fmt.Errorf("unsupported content codec 0x%x", codec)
+	}
+	hashCode, digest, err := parseMultihash(buff)
+	if err != nil {
+		return CID{}, err
+	}
+	return CID{version, codec, hashCode, digest}, nil
+}
+
+// CheckCIDBinding checks the CID references the content of the hash id, only a raw sha2-256 CID hashes the content
+// itself so its digest must be the hash id, every other CID hashes an IPFS block wrapping the content or uses another
+// hash function and cannot be checked, so it is rejected
+func CheckCIDBinding(cidField string, cid string, hashIDField string, hashID string) []FieldError {
+	parsedCID, err := ParseCID(cid)
+	if err != nil {
+		return []FieldError{{cidField, err.Error()}}
+	}
+	if parsedCID.Codec != CodecRaw || parsedCID.HashCode != HashSHA2_256 {
+		return []FieldError{{cidField, "must be a raw sha2-256 CIDv1 (b...) so it can be checked against " + hashIDField}}
+	}
+	expected, err := hex.DecodeString(hashID)
+	if err != nil || !bytes.Equal(expected, parsedCID.Digest) {
+		return []FieldError{{cidField, "sha2-256 digest of the CID does not match " + hashIDField}}
+	}
+	return nil
+}
+
+func parseMultihash(buff []byte) (uint64, []byte, error) {
+	hashCode, buff, err := readVarint(buff)
+	if err != nil {
+		return 0, nil, err
+	}
+	length, buff, err := readVarint(buff)
+	if err != nil {
+		return 0, nil, err
+	}
+	expected, found := multihashLengths[hashCode]
+	if !found {
+		return 0, nil, fmt.Errorf("unsupported multihash function 0x%x", hashCode)
+	}
+	if length != uint64(expected) || len(buff) != expected {
+		return 0, nil, fmt.Errorf("multihash digest must be %d bytes", expected)
+	}
+	return hashCode, buff, nil
+}
+
+func readVarint(buff []byte) (uint64, []byte, error) {
+	value, n := binary.Uvarint(buff)
+	if n <= 0 {
+		return 0, nil, errors.New("invalid varint in CID")
+	}
+	return value, buff[n:], nil
+}
+
+func decodeBase58(str string) ([]byte, error) {
+	value := new(big.Int)
+	radix := big.NewInt(58)
+	for _, char := range str {
+		index := strings.IndexRune(base58Alphabet, char)
+		if index < 0 {
+			return nil, errors.New("invalid base58 encoding")
+		}
+		value.Mul(value, radix)
+		value.Add(value, big.NewInt(int64(index)))
+	}
+
+	// every leading '1' is a leading zero byte
+	zeros := 0
+	for zeros < len(str) && str[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), value.Bytes()...), nil
+}
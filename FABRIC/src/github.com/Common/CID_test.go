@@ -0,0 +1,87 @@
+package common
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// the sha256 of "hello world" and the CIDs addressing it
+const (
+	helloDigest = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	helloCIDv0  = "QmaozNR7DZHQK1ZcU9p7QdrshMvXqWK6gpu5rmrkPdT3L4"
+	helloRawCID = "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e"
+	helloPBCID  = "bafybeifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e"
+)
+
+func TestParseCID(t *testing.T) {
+	tests := []struct {
+		name     string
+		cid      string
+		version  uint64
+		codec    uint64
+		hashCode uint64
+		wantErr  bool
+	}{
+		{name: "CIDv0", cid: helloCIDv0, version: 0, codec: CodecDagPB, hashCode: HashSHA2_256},
+		{name: "raw CIDv1", cid: helloRawCID, version: 1, codec: CodecRaw, hashCode: HashSHA2_256},
+		{name: "dag-pb CIDv1", cid: helloPBCID, version: 1, codec: CodecDagPB, hashCode: HashSHA2_256},
+		{name: "sha2-512 CIDv1", cid: "bafkrgqbqt3gerhas23vuzrapkdeqf4vu2dwxp3srdj6hvg6nhsug2tgyn6mj3u23yx7utftq3i2ckw2fwdh5qmhid5qf3t35yvkc5e5ottlw6", version: 1, codec: CodecRaw, hashCode: HashSHA2_512},
+		{name: "empty", cid: "", wantErr: true},
+		{name: "base58 CIDv1", cid: "zb2rhe5P4gXftAwvA4eXQ5HJwsER2owDyS9sKaQRRVQPn93bA", wantErr: true},
+		{name: "invalid base58", cid: "Qm0ozNR7DZHQK1ZcU9p7QdrshMvXqWK6gpu5rmrkPdT3L4", wantErr: true},
+		{name: "invalid base32", cid: "bafkrei1zjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e", wantErr: true},
+		{name: "CIDv2", cid: "bajkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e", wantErr: true},
+		{name: "unknown codec", cid: "bagmreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e", wantErr: true},
+		{name: "short digest", cid: "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := ParseCID(test.cid)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCID(%q) = %+v, want an error", test.cid, parsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCID(%q) failed - %s", test.cid, err)
+			}
+			if parsed.Version != test.version || parsed.Codec != test.codec || parsed.HashCode != test.hashCode {
+				t.Errorf("ParseCID(%q) = version %d codec 0x%x hash 0x%x, want version %d codec 0x%x hash 0x%x", test.cid,
+					parsed.Version, parsed.Codec, parsed.HashCode, test.version, test.codec, test.hashCode)
+			}
+			if test.hashCode == HashSHA2_256 && hex.EncodeToString(parsed.Digest) != helloDigest {
+				t.Errorf("ParseCID(%q) digest = %x, want %s", test.cid, parsed.Digest, helloDigest)
+			}
+		})
+	}
+}
+
+func TestCheckCIDBinding(t *testing.T) {
+	otherDigest := "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e"
+	tests := []struct {
+		name    string
+		cid     string
+		hashID  string
+		wantErr bool
+	}{
+		{name: "raw CID of the content", cid: helloRawCID, hashID: helloDigest},
+		{name: "raw CID with an upper case hash id", cid: helloRawCID, hashID: "B94D27B9934D3E08A52E52D7DA7DABFAC484EFE37A5380EE9088F7ACE2EFCDE9"},
+		{name: "raw CID of other content", cid: helloRawCID, hashID: otherDigest, wantErr: true},
+		{name: "CIDv0 cannot be checked", cid: helloCIDv0, hashID: helloDigest, wantErr: true},
+		{name: "dag-pb CIDv1 cannot be checked", cid: helloPBCID, hashID: helloDigest, wantErr: true},
+		{name: "sha2-512 raw CID cannot be checked", cid: "bafkrgqbqt3gerhas23vuzrapkdeqf4vu2dwxp3srdj6hvg6nhsug2tgyn6mj3u23yx7utftq3i2ckw2fwdh5qmhid5qf3t35yvkc5e5ottlw6", hashID: helloDigest, wantErr: true},
+		{name: "malformed CID", cid: "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n", hashID: helloDigest, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fieldErrors := CheckCIDBinding("AnswerCID", test.cid, "AnswerHashID", test.hashID)
+			if test.wantErr != (len(fieldErrors) > 0) {
+				t.Fatalf("CheckCIDBinding(%q, %q) = %v, want an error %t", test.cid, test.hashID, fieldErrors, test.wantErr)
+			}
+			if test.wantErr && fieldErrors[0].Field != "AnswerCID" {
+				t.Errorf("CheckCIDBinding(%q, %q) reported field %q, want AnswerCID", test.cid, test.hashID, fieldErrors[0].Field)
+			}
+		})
+	}
+}
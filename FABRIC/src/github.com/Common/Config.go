@@ -0,0 +1,118 @@
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// ============================================================================================================================
+// Configuration - one record per chaincode, changed by an admin through SetConfig
+// ============================================================================================================================
+
+// ConfigObjectType the config is stored under a composite key so it can never collide with a record id
+const ConfigObjectType = "config"
+
+// EventConfigChanged is raised by SetConfig, its payload is the new config
+const EventConfigChanged = "ConfigChanged"
+
+// DefaultMinEvaluatorRepu the reputation an evaluator needs for a question without a MinEvaluatorRepu of its own, more
+// than the 1000 evaluators needed before the minimum was introduced, the default floor of the Question chaincode
+const DefaultMinEvaluatorRepu = 1001
+
+// AccountConfig the settings of the chaincodes keeping accounts with a secret, the Student and Evaluator chaincodes
+type AccountConfig struct {
+	MaxFailedAuthAttempts   int `json:"MaxFailedAuthAttempts" validate:"minimum=1,maximum=100"`
+	FailedAuthWindowMinutes int `json:"FailedAuthWindowMinutes" validate:"minimum=1,maximum=10080"`
+	LockoutMinutes          int `json:"LockoutMinutes" validate:"minimum=1,maximum=525600"`
+	// a reputation bump for a tech the record does not have creates the tech instead of failing with NOT_FOUND
+	AutoCreateTechOnBump bool `json:"AutoCreateTechOnBump" metadata:",optional"`
+	// the names the chaincodes allowed to settle reputation and accuracy and to record the answered questions were
+	// instantiated with, the Answers chaincode, see AssertTrustedCaller, none until an admin sets them
+	TrustedChaincodes []string `json:"TrustedChaincodes" metadata:",optional" validate:"maxlength=64,format=chaincode"`
+}
+
+// DefaultAccountConfig the account settings until an admin sets them
+var DefaultAccountConfig = AccountConfig{
+	MaxFailedAuthAttempts:   5,
+	FailedAuthWindowMinutes: 15,
+	LockoutMinutes:          60,
+	AutoCreateTechOnBump:    false,
+	TrustedChaincodes:       nil,
+}
+
+// ReadConfig reads the config from the world state into config, which holds the defaults and is left as it is
+// if no config was set
+func ReadConfig(ctx TransactionContextInterface, config interface{}) error {
+	configKey, err := ctx.GetStub().CreateCompositeKey(ConfigObjectType, []string{})
+	if err != nil {
+		return InternalError(err, "unable to create the config key")
+	}
+	configAsBytes, err := ctx.GetStub().GetState(configKey)
+	if err != nil {
+		return InternalError(err, "error in finding the config")
+	}
+	if configAsBytes == nil {
+		return nil
+	}
+	err = json.Unmarshal(configAsBytes, config)
+	if err != nil {
+		return InternalError(err, "unable to unmarshall the config")
+	}
+	return nil
+}
+
+// WriteConfig writes the config to the world state
+func WriteConfig(ctx TransactionContextInterface, config interface{}) error {
+	configKey, err := ctx.GetStub().CreateCompositeKey(ConfigObjectType, []string{})
+	if err != nil {
+		return InternalError(err, "unable to create the config key")
+	}
+	buff, err := json.Marshal(config)
+	if err != nil {
+		return InternalError(err, "unable to convert the config to json")
+	}
+	err = ctx.GetStub().PutState(configKey, buff)
+	if err != nil {
+		return InternalError(err, "unable to write the config")
+	}
+	return nil
+}
+
+// SetConfig admin only, replaces the config, the optional fields of config left out are zero and take their value
+// from defaults, a config type has no optional field whose zero value differs from its default and is valid
+func SetConfig(ctx TransactionContextInterface, config interface{}, defaults interface{}) error {
+	err := AssertAdmin(ctx, "SetConfig")
+	if err != nil {
+		return err
+	}
+
+	configValue := reflect.ValueOf(config).Elem()
+	defaultsValue := reflect.ValueOf(defaults)
+	for i := 0; i < configValue.NumField(); i++ {
+		if configValue.Field(i).IsZero() {
+			configValue.Field(i).Set(defaultsValue.Field(i))
+		}
+	}
+
+	err = WriteConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventConfigChanged, config)
+	return nil
+}
+
+// GetAccountConfig reads the account settings, the defaults if no config was set
+func GetAccountConfig(ctx TransactionContextInterface) (*AccountConfig, error) {
+	config := DefaultAccountConfig
+	err := ReadConfig(ctx, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SetAccountConfig admin only, replaces the account settings
+func SetAccountConfig(ctx TransactionContextInterface, config AccountConfig) error {
+	return SetConfig(ctx, &config, DefaultAccountConfig)
+}
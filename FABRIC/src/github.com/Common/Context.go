@@ -0,0 +1,86 @@
+// Package common holds the helpers shared by the chaincodes, each chaincode embeds its TransactionContext and
+// extends its TransactionContextInterface
+package common
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================================================================================
+// Transaction Context - the helpers every chaincode's transaction context embeds
+// ============================================================================================================================
+
+// LedgerTimeFormat the format every time is stored in on the ledger, it sorts as a string
+const LedgerTimeFormat = "20060102150405"
+
+// TransactionContextInterface the helpers every chaincode's own context interface extends
+type TransactionContextInterface interface {
+	contractapi.TransactionContextInterface
+	GetTxTime() (string, error)
+	CallChaincode(chaincodeName string, functionName string, request interface{}) ([]byte, error)
+	EmitEvent(eventName string, payload interface{})
+	FlushEvents() error
+}
+
+// TransactionContext implementation of TransactionContextInterface, embedded by the TransactionContext of every chaincode
+type TransactionContext struct {
+	contractapi.TransactionContext
+	events []ChaincodeEvent
+}
+
+// GetTxTime returns the transaction timestamp in the ledger's date format, identical on every endorser
+func (ctx *TransactionContext) GetTxTime() (string, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", InternalError(err, "unable to read the transaction timestamp")
+	}
+	return time.Unix(txTimestamp.GetSeconds(), int64(txTimestamp.GetNanos())).UTC().Format(LedgerTimeFormat), nil
+}
+
+func toChaincodeArgs(args ...string) [][]byte {
+	bargs := make([][]byte, len(args))
+	for i, arg := range args {
+		bargs[i] = []byte(arg)
+	}
+	return bargs
+}
+
+// CallChaincode invokes a function of another chaincode on the same channel with the request as its JSON request object
+// and returns its payload, a failure is a DEPENDENCY_FAILED envelope with the other chaincode's error as the cause
+func (ctx *TransactionContext) CallChaincode(chaincodeName string, functionName string, request interface{}) ([]byte, error) {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, InternalError(err, "unable to convert the %s request to json", functionName)
+	}
+	invokeArgs := toChaincodeArgs(functionName, string(requestJSON))
+
+	response := ctx.GetStub().InvokeChaincode(chaincodeName, invokeArgs, "")
+	if response.Status != shim.OK {
+		chaincodeError := NewError(ErrDependencyFailed, "Failed to invoke %s on chaincode %s", functionName, chaincodeName)
+		chaincodeError.Cause = ParseError(response.Message)
+		return nil, chaincodeError
+	}
+	return response.Payload, nil
+}
+
+// AddMinutes adds minutes to a time in the ledger's date format
+func AddMinutes(ledgerTime string, minutes int) (string, error) {
+	parsed, err := time.Parse(LedgerTimeFormat, ledgerTime)
+	if err != nil {
+		return "", InternalError(err, "unable to parse the time %s", ledgerTime)
+	}
+	return parsed.Add(time.Duration(minutes) * time.Minute).Format(LedgerTimeFormat), nil
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ============================================================================================================================
+// Error Envelope - every function fails with a ChaincodeError so clients can switch on a stable code
+// ============================================================================================================================
+
+// stable error codes of the error envelope
+const (
+	ErrNotFound         = "NOT_FOUND"
+	ErrAlreadyExists    = "ALREADY_EXISTS"
+	ErrUnauthorized     = "UNAUTHORIZED"
+	ErrForbidden        = "FORBIDDEN"
+	ErrValidationFailed = "VALIDATION_FAILED"
+	ErrInvalidState     = "INVALID_STATE"
+	ErrLocked           = "LOCKED"
+	ErrDependencyFailed = "DEPENDENCY_FAILED"
+	ErrUnknownFunction  = "UNKNOWN_FUNCTION"
+	ErrInternal         = "INTERNAL"
+)
+
+// ChaincodeError is the error envelope returned by every chaincode function, its Error() is the JSON envelope itself
+type ChaincodeError struct {
+	Code    string          `json:"Code"`
+	Message string          `json:"Message"`
+	Details interface{}     `json:"Details,omitempty"`
+	Cause   *ChaincodeError `json:"Cause,omitempty"`
+}
+
+func (e *ChaincodeError) Error() string {
+	buff, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Sprintf("{\"Code\":\"%s\",\"Message\":%q}", e.Code, e.Message)
+	}
+	return string(buff)
+}
+
+// NewError creates an error envelope with the code and a formatted message
+func NewError(code string, format string, args ...interface{}) *ChaincodeError {
+	chaincodeError := &ChaincodeError{Code: code, Message: fmt.Sprintf(format, args...)}
+	fmt.Println(chaincodeError.Error())
+	return chaincodeError
+}
+
+// InternalError wraps an unexpected error (ledger access, marshalling) into the envelope
+func InternalError(err error, format string, args ...interface{}) *ChaincodeError {
+	if chaincodeError, ok := err.(*ChaincodeError); ok {
+		return chaincodeError
+	}
+	return NewError(ErrInternal, format+" - %s", append(args, err.Error())...)
+}
+
+// ParseError reads an error envelope back from an error message, messages that are not an envelope become INTERNAL
+func ParseError(message string) *ChaincodeError {
+	chaincodeError := &ChaincodeError{}
+	err := json.Unmarshal([]byte(message), chaincodeError)
+	if err != nil || chaincodeError.Code == "" {
+		return &ChaincodeError{Code: ErrInternal, Message: message}
+	}
+	return chaincodeError
+}
+
+// LiftDependencyError reports a failed call to another chaincode with the other chaincode's own code when it is one
+// of codes, so e.g. a missing question is NOT_FOUND rather than DEPENDENCY_FAILED, the original error stays the cause
+func LiftDependencyError(err error, codes []string, format string, args ...interface{}) error {
+	chaincodeError, ok := err.(*ChaincodeError)
+	if !ok || chaincodeError.Code != ErrDependencyFailed || chaincodeError.Cause == nil || !stringInSlice(chaincodeError.Cause.Code, codes) {
+		return err
+	}
+	liftedError := NewError(chaincodeError.Cause.Code, format, args...)
+	liftedError.Cause = chaincodeError.Cause
+	return liftedError
+}
+
+// UnknownTransaction is called by the contract api for functions the chaincode does not have
+func UnknownTransaction(ctx TransactionContextInterface) error {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	return NewError(ErrUnknownFunction, "Received unknown invoke function name - '%s'", function)
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ============================================================================================================================
+// Chaincode Events - functions raise typed events which are set on the transaction once it succeeded
+// ============================================================================================================================
+
+// AggregatedEventName is the name of the event set when a transaction raised more than one event,
+// fabric keeps a single event per transaction
+const AggregatedEventName = "TransactionEvents"
+
+// ChaincodeEvent one typed event raised by a function
+type ChaincodeEvent struct {
+	EventName string      `json:"EventName"`
+	Payload   interface{} `json:"Payload"`
+}
+
+// AggregatedEvent payload of the TransactionEvents event, the events in the order they were raised
+type AggregatedEvent struct {
+	TxID   string           `json:"TxID"`
+	Events []ChaincodeEvent `json:"Events"`
+}
+
+// EmitEvent raises an event, it is only set on the transaction by AfterTransaction
+func (ctx *TransactionContext) EmitEvent(eventName string, payload interface{}) {
+	fmt.Println("raising event - " + eventName)
+	ctx.events = append(ctx.events, ChaincodeEvent{eventName, payload})
+}
+
+// FlushEvents sets the raised events on the transaction, a single event under its own name
+// and several events as one TransactionEvents event
+func (ctx *TransactionContext) FlushEvents() error {
+	if len(ctx.events) == 0 {
+		return nil
+	}
+
+	eventName := ctx.events[0].EventName
+	var payload interface{} = ctx.events[0].Payload
+	if len(ctx.events) > 1 {
+		eventName = AggregatedEventName
+		payload = AggregatedEvent{ctx.GetStub().GetTxID(), ctx.events}
+	}
+
+	buff, err := json.Marshal(payload)
+	if err != nil {
+		return InternalError(err, "unable to convert the %s event to json", eventName)
+	}
+	err = ctx.GetStub().SetEvent(eventName, buff)
+	if err != nil {
+		return InternalError(err, "unable to set the %s event", eventName)
+	}
+	ctx.events = nil
+	return nil
+}
+
+// AfterTransaction is called by the contract api once a function succeeded
+func AfterTransaction(ctx TransactionContextInterface) error {
+	return ctx.FlushEvents()
+}
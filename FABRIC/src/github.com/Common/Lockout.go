@@ -0,0 +1,99 @@
+package common
+
+// ============================================================================================================================
+// Authentication Lockout - failed authentications are counted on the record and lock it for a while once too many
+// happened within the configured window, only transactions that commit can count a failure
+// ============================================================================================================================
+
+// names of the events raised for authentications
+const (
+	EventAuthenticationFailed = "AuthenticationFailed"
+	EventAccountLocked        = "AccountLocked"
+	EventAccountUnlocked      = "AccountUnlocked"
+)
+
+// AuthState the failed authentications of a student or evaluator, embedded in its record
+type AuthState struct {
+	FailedAuthAttempts int    `json:"FailedAuthAttempts,omitempty"`
+	FailedAuthSince    string `json:"FailedAuthSince,omitempty"`
+	LockedUntil        string `json:"LockedUntil,omitempty"`
+}
+
+// AuthenticationResult result of an authentication audit transaction, a wrong secret is a result and not an error
+// so the failure is committed
+type AuthenticationResult struct {
+	Authenticated      bool   `json:"Authenticated"`
+	FailedAuthAttempts int    `json:"FailedAuthAttempts"`
+	LockedUntil        string `json:"LockedUntil,omitempty"`
+}
+
+// AuthenticationFailedEvent payload of AuthenticationFailed
+type AuthenticationFailedEvent struct {
+	SubjectType        string `json:"SubjectType"`
+	SubjectID          string `json:"SubjectID"`
+	FailedAuthAttempts int    `json:"FailedAuthAttempts"`
+	FailedOn           string `json:"FailedOn"`
+}
+
+// AccountLockedEvent payload of AccountLocked, raised together with the AuthenticationFailed that locked the account
+type AccountLockedEvent struct {
+	SubjectType string `json:"SubjectType"`
+	SubjectID   string `json:"SubjectID"`
+	LockedUntil string `json:"LockedUntil"`
+}
+
+// AccountUnlockedEvent payload of AccountUnlocked, raised when an admin unlocks an account
+type AccountUnlockedEvent struct {
+	SubjectType string `json:"SubjectType"`
+	SubjectID   string `json:"SubjectID"`
+	UnlockedBy  string `json:"UnlockedBy"`
+	UnlockedOn  string `json:"UnlockedOn"`
+}
+
+// IsLocked whether the account is locked at the given time in the ledger's date format
+func (state *AuthState) IsLocked(now string) bool {
+	return state.LockedUntil != "" && now < state.LockedUntil
+}
+
+// Reset forgets every failed authentication and lifts the lock
+func (state *AuthState) Reset() {
+	*state = AuthState{}
+}
+
+// RecordFailedAuthentication counts a failed authentication and locks the account when it reached the configured
+// maximum within the window, it raises the events but leaves writing the record to the caller
+func RecordFailedAuthentication(ctx TransactionContextInterface, state *AuthState, subjectType string, subjectID string, now string) error {
+	config, err := GetAccountConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	windowEnd := ""
+	if state.FailedAuthSince != "" {
+		windowEnd, err = AddMinutes(state.FailedAuthSince, config.FailedAuthWindowMinutes)
+		if err != nil {
+			return err
+		}
+	}
+	// a new window starts with the first failure after the previous window or lock ended
+	if state.FailedAuthSince == "" || now > windowEnd || (state.LockedUntil != "" && !state.IsLocked(now)) {
+		state.Reset()
+		state.FailedAuthSince = now
+	}
+	state.FailedAuthAttempts++
+	ctx.EmitEvent(EventAuthenticationFailed, AuthenticationFailedEvent{subjectType, subjectID, state.FailedAuthAttempts, now})
+
+	if state.FailedAuthAttempts >= config.MaxFailedAuthAttempts {
+		state.LockedUntil, err = AddMinutes(now, config.LockoutMinutes)
+		if err != nil {
+			return err
+		}
+		ctx.EmitEvent(EventAccountLocked, AccountLockedEvent{subjectType, subjectID, state.LockedUntil})
+	}
+	return nil
+}
+
+// LockedError the LOCKED envelope for an account that is locked
+func LockedError(subjectType string, subjectID string, lockedUntil string) *ChaincodeError {
+	return NewError(ErrLocked, "%s %s is locked until %s after too many failed authentications", subjectType, subjectID, lockedUntil)
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+func TestRecordFailedAuthentication(t *testing.T) {
+	stub := shimtest.NewMockStub("Common", nil)
+	stub.MockTransactionStart("config")
+	ctx := &TransactionContext{}
+	ctx.SetStub(stub)
+	err := WriteConfig(ctx, AccountConfig{MaxFailedAuthAttempts: 3, FailedAuthWindowMinutes: 15, LockoutMinutes: 60})
+	if err != nil {
+		t.Fatalf("WriteConfig failed - %s", err)
+	}
+	stub.MockTransactionEnd("config")
+
+	tests := []struct {
+		name     string
+		state    AuthState
+		now      string
+		expected AuthState
+		events   []string
+	}{
+		{
+			name:     "first failure opens a window",
+			state:    AuthState{},
+			now:      "20240101120000",
+			expected: AuthState{FailedAuthAttempts: 1, FailedAuthSince: "20240101120000"},
+			events:   []string{EventAuthenticationFailed},
+		},
+		{
+			name:     "failure within the window counts",
+			state:    AuthState{FailedAuthAttempts: 1, FailedAuthSince: "20240101120000"},
+			now:      "20240101121500",
+			expected: AuthState{FailedAuthAttempts: 2, FailedAuthSince: "20240101120000"},
+			events:   []string{EventAuthenticationFailed},
+		},
+		{
+			name:     "failure after the window opens a new one",
+			state:    AuthState{FailedAuthAttempts: 2, FailedAuthSince: "20240101120000"},
+			now:      "20240101121501",
+			expected: AuthState{FailedAuthAttempts: 1, FailedAuthSince: "20240101121501"},
+			events:   []string{EventAuthenticationFailed},
+		},
+		{
+			name:     "maximum within the window locks",
+			state:    AuthState{FailedAuthAttempts: 2, FailedAuthSince: "20240101120000"},
+			now:      "20240101121000",
+			expected: AuthState{FailedAuthAttempts: 3, FailedAuthSince: "20240101120000", LockedUntil: "20240101131000"},
+			events:   []string{EventAuthenticationFailed, EventAccountLocked},
+		},
+		{
+			name:     "failure after the lock ended opens a new window",
+			state:    AuthState{FailedAuthAttempts: 3, FailedAuthSince: "20240101120000", LockedUntil: "20240101131000"},
+			now:      "20240101131000",
+			expected: AuthState{FailedAuthAttempts: 1, FailedAuthSince: "20240101131000"},
+			events:   []string{EventAuthenticationFailed},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := &TransactionContext{}
+			ctx.SetStub(stub)
+			state := test.state
+			err := RecordFailedAuthentication(ctx, &state, "STUDENT", "s1", test.now)
+			if err != nil {
+				t.Fatalf("RecordFailedAuthentication failed - %s", err)
+			}
+			if state != test.expected {
+				t.Errorf("RecordFailedAuthentication(%+v, %s) = %+v, want %+v", test.state, test.now, state, test.expected)
+			}
+			var events []string
+			for _, event := range ctx.events {
+				events = append(events, event.EventName)
+			}
+			if !reflect.DeepEqual(events, test.events) {
+				t.Errorf("RecordFailedAuthentication(%+v, %s) raised %q, want %q", test.state, test.now, events, test.events)
+			}
+		})
+	}
+}
+
+func TestIsLocked(t *testing.T) {
+	tests := []struct {
+		name        string
+		lockedUntil string
+		now         string
+		expected    bool
+	}{
+		{name: "never locked", lockedUntil: "", now: "20240101120000", expected: false},
+		{name: "before the lock ends", lockedUntil: "20240101130000", now: "20240101125959", expected: true},
+		{name: "when the lock ends", lockedUntil: "20240101130000", now: "20240101130000", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := AuthState{LockedUntil: test.lockedUntil}
+			if state.IsLocked(test.now) != test.expected {
+				t.Errorf("IsLocked(%s) with LockedUntil %q = %t, want %t", test.now, test.lockedUntil, !test.expected, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ============================================================================================================================
+// Secret Hashing - every endorser has to write the same hash of a secret, so the salt is derived from the transaction id
+// instead of drawn at random, and the key derivation is PBKDF2-HMAC-SHA256 with a cost the endorsement can afford
+// ============================================================================================================================
+
+// secretHashScheme prefixes a stored hash, $<iterations>$<hex salt>$<hex digest> follow it
+const secretHashScheme = "pbkdf2-sha256"
+
+// SecretHashIterations the PBKDF2 iterations of a new hash
+const SecretHashIterations = 10000
+
+// HashSecret the hash of the secret to store on the record, salted by the transaction id so it is the same on every
+// endorser and differs between transactions
+func HashSecret(ctx TransactionContextInterface, secret string) string {
+	salt := sha256.Sum256([]byte("secret:" + ctx.GetStub().GetTxID()))
+	return hashSecret(secret, salt[:16], SecretHashIterations)
+}
+
+// CheckSecret whether the secret matches the stored hash, the bcrypt hashes of the records created before the hashes
+// were derived from the transaction id are checked as well
+func CheckSecret(secret string, stored string) bool {
+	parts := strings.Split(stored, "$")
+	if len(parts) != 4 || parts[0] != secretHashScheme {
+		return strings.HasPrefix(stored, "$2") && bcrypt.CompareHashAndPassword([]byte(stored), []byte(secret)) == nil
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret, salt, iterations)), []byte(stored)) == 1
+}
+
+func hashSecret(secret string, salt []byte, iterations int) string {
+	return secretHashScheme + "$" + strconv.Itoa(iterations) + "$" + hex.EncodeToString(salt) + "$" +
+		hex.EncodeToString(pbkdf2SHA256([]byte(secret), salt, iterations))
+}
+
+// pbkdf2SHA256 the first block of PBKDF2 with HMAC-SHA256 (RFC 8018), a key of 32 bytes
+func pbkdf2SHA256(password []byte, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, password)
+	prf.Write(salt)
+	blockIndex := make([]byte, 4)
+	binary.BigEndian.PutUint32(blockIndex, 1)
+	prf.Write(blockIndex)
+	u := prf.Sum(nil)
+	key := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
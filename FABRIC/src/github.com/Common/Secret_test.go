@@ -0,0 +1,65 @@
+package common
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+func TestPBKDF2SHA256(t *testing.T) {
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		expected   string
+	}{
+		{password: "passwd", salt: "salt", iterations: 1, expected: "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{password: "password", salt: "salt", iterations: 4096, expected: "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+	for _, test := range tests {
+		key := hex.EncodeToString(pbkdf2SHA256([]byte(test.password), []byte(test.salt), test.iterations))
+		if key != test.expected {
+			t.Errorf("pbkdf2SHA256(%q, %q, %d) = %s, want %s", test.password, test.salt, test.iterations, key, test.expected)
+		}
+	}
+}
+
+func TestHashSecret(t *testing.T) {
+	stub := shimtest.NewMockStub("Common", nil)
+	ctx := &TransactionContext{}
+	ctx.SetStub(stub)
+
+	stub.MockTransactionStart("tx1")
+	hash := HashSecret(ctx, "correct horse")
+	if again := HashSecret(ctx, "correct horse"); again != hash {
+		t.Errorf("HashSecret differs within a transaction: %s and %s", hash, again)
+	}
+	stub.MockTransactionEnd("tx1")
+	stub.MockTransactionStart("tx2")
+	if other := HashSecret(ctx, "correct horse"); other == hash {
+		t.Errorf("HashSecret is the same in two transactions: %s", hash)
+	}
+	stub.MockTransactionEnd("tx2")
+
+	tests := []struct {
+		name     string
+		secret   string
+		stored   string
+		expected bool
+	}{
+		{name: "matching secret", secret: "correct horse", stored: hash, expected: true},
+		{name: "wrong secret", secret: "battery staple", stored: hash, expected: false},
+		{name: "fewer iterations", secret: "correct horse", stored: strings.Replace(hash, "$10000$", "$1$", 1), expected: false},
+		{name: "malformed salt", secret: "correct horse", stored: "pbkdf2-sha256$1$zz$00", expected: false},
+		{name: "empty hash", secret: "", stored: "", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if CheckSecret(test.secret, test.stored) != test.expected {
+				t.Errorf("CheckSecret(%q, %q) = %t, want %t", test.secret, test.stored, !test.expected, test.expected)
+			}
+		})
+	}
+}
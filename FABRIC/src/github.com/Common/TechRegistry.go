@@ -0,0 +1,33 @@
+package common
+
+import (
+	"encoding/json"
+)
+
+// ============================================================================================================================
+// Tech Registry - tech names are resolved through the Techs chaincode to the canonical tech id before they are stored
+// or compared, so "Go", "golang" and "go" are the same tech
+// ============================================================================================================================
+
+// TechResolution the canonical tech a name resolves to, returned by ResolveTech of the Techs chaincode
+type TechResolution struct {
+	TechID    string   `json:"TechID"`
+	Name      string   `json:"Name"`
+	ParentID  string   `json:"ParentID"`
+	Ancestors []string `json:"Ancestors"`
+}
+
+// ResolveTech resolves the tech name, its canonical id or any alias, an unknown tech is NOT_FOUND
+func ResolveTech(ctx TransactionContextInterface, techsChaincode string, techName string) (*TechResolution, error) {
+	resolutionBytes, err := ctx.CallChaincode(techsChaincode, "ResolveTech", map[string]string{"TechName": techName})
+	if err != nil {
+		return nil, LiftDependencyError(err, []string{ErrNotFound}, "unable to resolve the tech %s", techName)
+	}
+
+	resolution := TechResolution{}
+	err = json.Unmarshal(resolutionBytes, &resolution)
+	if err != nil {
+		return nil, InternalError(err, "unable to unmarshall the resolution of tech %s", techName)
+	}
+	return &resolution, nil
+}
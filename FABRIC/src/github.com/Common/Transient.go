@@ -0,0 +1,32 @@
+package common
+
+// ============================================================================================================================
+// Transient Values - secrets, and whatever else must not be written to the ledger with the transaction, travel in the
+// transient map of the proposal and never in the arguments, a chaincode called by another one sees the same transient map
+// ============================================================================================================================
+
+// GetTransientSecret reads the secret stored under the name in the transient map, a missing secret or one that is not
+// between minLength and maxLength characters fails with the VALIDATION_FAILED envelope of the function
+func GetTransientSecret(ctx TransactionContextInterface, function string, name string, minLength int, maxLength int) (string, error) {
+	return GetTransientValue(ctx, function, FieldSchema{Name: name, Type: TypeString, Required: true, MinLength: minLength, MaxLength: maxLength})
+}
+
+// GetTransientValue reads the string stored in the transient map under the name of the field schema, a missing value or
+// one that does not match the schema fails with the VALIDATION_FAILED envelope of the function
+func GetTransientValue(ctx TransactionContextInterface, function string, fieldSchema FieldSchema) (string, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", InternalError(err, "unable to read the transient map")
+	}
+	value, found := transient[fieldSchema.Name]
+	if !found || len(value) == 0 {
+		return "", ValidationError(function, []FieldError{{Field: fieldSchema.Name, Message: "is required in the transient map"}})
+	}
+
+	str := string(value)
+	fieldErrors := validateString(fieldSchema.Name, fieldSchema, str)
+	if len(fieldErrors) > 0 {
+		return "", ValidationError(function, fieldErrors)
+	}
+	return str, nil
+}
@@ -0,0 +1,298 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ============================================================================================================================
+// Request Validation - every function takes a single request object, a struct the contract api unmarshals the JSON
+// argument into, its fields are required unless tagged metadata:",optional" and declare their other constraints with a
+// validate tag, e.g. validate:"maxlength=64,format=id", the argument is checked against them before the contract api
+// reads it so a mismatch fails with the VALIDATION_FAILED envelope
+// ============================================================================================================================
+
+// field types of a request schema
+const (
+	TypeString      = "string"
+	TypeInteger     = "integer"
+	TypeBoolean     = "boolean"
+	TypeStringArray = "array"
+)
+
+// field formats of a request schema
+const (
+	FormatID        = "id"
+	FormatHashID    = "hash"
+	FormatCID       = "cid"
+	FormatTech      = "tech"
+	FormatTechID    = "techid"
+	FormatChaincode = "chaincode"
+)
+
+var formatPatterns = map[string]*regexp.Regexp{
+	FormatID:        regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.@-]*$`),
+	FormatHashID:    regexp.MustCompile(`^[a-fA-F0-9]{64}$`),
+	FormatCID:       regexp.MustCompile(`^(Qm[1-9A-HJ-NP-Za-km-z]{44}|b[a-z2-7]{58,})$`),
+	FormatTech:      regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 +#._-]*$`),
+	FormatTechID:    regexp.MustCompile(`^[a-z0-9][a-z0-9+#._-]*$`),
+	FormatChaincode: regexp.MustCompile(`^[A-Za-z0-9]+([-_][A-Za-z0-9]+)*$`),
+}
+
+var formatDescriptions = map[string]string{
+	FormatID:        "must start with a letter or digit and contain only letters, digits, '_', '.', '@' or '-'",
+	FormatHashID:    "must be a hex encoded sha256 digest",
+	FormatCID:       "must be an IPFS CIDv0 (Qm...) or a base32 CIDv1 (b...)",
+	FormatTech:      "must start with a letter or digit and contain only letters, digits, spaces, '+', '#', '.', '_' or '-'",
+	FormatTechID:    "must start with a lowercase letter or digit and contain only lowercase letters, digits, '+', '#', '.', '_' or '-'",
+	FormatChaincode: "must be a valid chaincode name",
+}
+
+// FieldSchema declares the constraints of one field of a request object
+// Minimum and Maximum are only checked for integers when one of them is set
+type FieldSchema struct {
+	Name      string `json:"Name"`
+	Type      string `json:"Type"`
+	Required  bool   `json:"Required"`
+	MinLength int    `json:"MinLength"`
+	MaxLength int    `json:"MaxLength"`
+	Minimum   int    `json:"Minimum"`
+	Maximum   int    `json:"Maximum"`
+	Format    string `json:"Format"`
+}
+
+// RequestSchema the declared schema of the request object of one function
+type RequestSchema struct {
+	Function string        `json:"Function"`
+	Fields   []FieldSchema `json:"Fields"`
+}
+
+// FieldError describes why one field of a request object was rejected
+type FieldError struct {
+	Field   string `json:"Field"`
+	Message string `json:"Message"`
+}
+
+// ValidationError is the VALIDATION_FAILED envelope carrying one FieldError per offending field
+func ValidationError(function string, fieldErrors []FieldError) *ChaincodeError {
+	chaincodeError := NewError(ErrValidationFailed, "request does not match the %s schema", function)
+	chaincodeError.Details = fieldErrors
+	return chaincodeError
+}
+
+// SchemaOf the schema of the request object of the function declared by the tags of the request type, the fields of
+// embedded structs are fields of the request, panics when a length or bound of a tag is not a number
+func SchemaOf(function string, requestType reflect.Type) RequestSchema {
+	return RequestSchema{Function: function, Fields: schemaFields(requestType)}
+}
+
+func schemaFields(requestType reflect.Type) []FieldSchema {
+	fields := []FieldSchema{}
+	for i := 0; i < requestType.NumField(); i++ {
+		field := requestType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, schemaFields(field.Type)...)
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldSchema := FieldSchema{Name: name, Required: !strings.Contains(field.Tag.Get("metadata"), ",optional")}
+		switch field.Type.Kind() {
+		case reflect.String:
+			fieldSchema.Type = TypeString
+		case reflect.Int:
+			fieldSchema.Type = TypeInteger
+		case reflect.Bool:
+			fieldSchema.Type = TypeBoolean
+		case reflect.Slice:
+			fieldSchema.Type = TypeStringArray
+		}
+		for _, constraint := range strings.Split(field.Tag.Get("validate"), ",") {
+			key, value := constraint, ""
+			if index := strings.Index(constraint, "="); index >= 0 {
+				key, value = constraint[:index], constraint[index+1:]
+			}
+			number := 0
+			if key == "minlength" || key == "maxlength" || key == "minimum" || key == "maximum" {
+				var err error
+				number, err = strconv.Atoi(value)
+				if err != nil {
+					// a typo in a tag of the request type, not a bad request
+					panic(fmt.Sprintf("invalid %s constraint of %s.%s - %s", key, requestType.Name(), field.Name, err))
+				}
+			}
+			switch key {
+			case "minlength":
+				fieldSchema.MinLength = number
+			case "maxlength":
+				fieldSchema.MaxLength = number
+			case "minimum":
+				fieldSchema.Minimum = number
+			case "maximum":
+				fieldSchema.Maximum = number
+			case "format":
+				fieldSchema.Format = value
+			}
+		}
+		fields = append(fields, fieldSchema)
+	}
+	return fields
+}
+
+// RequestSchemas the schema of every function of the contract that takes a request object, ordered by function name
+func RequestSchemas(contract interface{}) []RequestSchema {
+	schemas := []RequestSchema{}
+	contractType := reflect.TypeOf(contract)
+	for i := 0; i < contractType.NumMethod(); i++ {
+		method := contractType.Method(i)
+		// the receiver, the transaction context and the request object
+		if method.Type.NumIn() == 3 && method.Type.In(2).Kind() == reflect.Struct {
+			schemas = append(schemas, SchemaOf(method.Name, method.Type.In(2)))
+		}
+	}
+	return schemas
+}
+
+// ValidateRequest checks the request JSON against the schema
+func ValidateRequest(requestJSON string, schema RequestSchema) error {
+	fields := map[string]json.RawMessage{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(requestJSON)))
+	decoder.UseNumber()
+	err := decoder.Decode(&fields)
+	if err != nil {
+		return ValidationError(schema.Function, []FieldError{{Field: "", Message: "request must be a JSON object - " + err.Error()}})
+	}
+
+	var fieldErrors []FieldError
+	known := map[string]bool{}
+	for _, fieldSchema := range schema.Fields {
+		known[fieldSchema.Name] = true
+		value, found := fields[fieldSchema.Name]
+		if !found || string(value) == "null" {
+			if fieldSchema.Required {
+				fieldErrors = append(fieldErrors, FieldError{fieldSchema.Name, "is required"})
+			}
+			continue
+		}
+		fieldErrors = append(fieldErrors, validateField(fieldSchema, value)...)
+	}
+
+	// unknown fields are reported sorted so every endorser returns the same error
+	var unknown []string
+	for name := range fields {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fieldErrors = append(fieldErrors, FieldError{name, "is not a field of " + schema.Function + " request"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return ValidationError(schema.Function, fieldErrors)
+	}
+	return nil
+}
+
+// BeforeTransaction checks the function of the contract receives its single JSON request object and that the object
+// matches the request type's schema, so a bad request fails with the envelope instead of the contract api's own error,
+// functions that only take the transaction context take no argument
+func BeforeTransaction(contract interface{}) func(ctx TransactionContextInterface) error {
+	return func(ctx TransactionContextInterface) error {
+		function, args := ctx.GetStub().GetFunctionAndParameters()
+		fmt.Println(" ")
+		fmt.Println("starting invoke, for - " + function)
+
+		// functions can be called namespaced by the contract name as well
+		function = function[strings.LastIndex(function, ":")+1:]
+
+		method := reflect.ValueOf(contract).MethodByName(function)
+		if !method.IsValid() {
+			// answered by UnknownTransaction
+			return nil
+		}
+		expected := method.Type().NumIn() - 1
+		if len(args) != expected {
+			return NewError(ErrValidationFailed, "%s expects %d JSON request object, got %d arguments", function, expected, len(args))
+		}
+		if expected == 0 {
+			return nil
+		}
+		return ValidateRequest(args[0], SchemaOf(function, method.Type().In(1)))
+	}
+}
+
+func validateField(fieldSchema FieldSchema, value json.RawMessage) []FieldError {
+	switch fieldSchema.Type {
+	case TypeString:
+		var str string
+		if json.Unmarshal(value, &str) != nil {
+			return []FieldError{{fieldSchema.Name, "must be a string"}}
+		}
+		return validateString(fieldSchema.Name, fieldSchema, str)
+
+	case TypeStringArray:
+		var strs []string
+		if json.Unmarshal(value, &strs) != nil {
+			return []FieldError{{fieldSchema.Name, "must be an array of strings"}}
+		}
+		var fieldErrors []FieldError
+		for i, str := range strs {
+			fieldErrors = append(fieldErrors, validateString(fieldSchema.Name+"["+strconv.Itoa(i)+"]", fieldSchema, str)...)
+		}
+		return fieldErrors
+
+	case TypeInteger:
+		var number json.Number
+		if bytes.HasPrefix(value, []byte(`"`)) || json.Unmarshal(value, &number) != nil {
+			return []FieldError{{fieldSchema.Name, "must be an integer"}}
+		}
+		integer, err := strconv.Atoi(number.String())
+		if err != nil {
+			return []FieldError{{fieldSchema.Name, "must be an integer"}}
+		}
+		if (fieldSchema.Minimum != 0 || fieldSchema.Maximum != 0) && (integer < fieldSchema.Minimum || integer > fieldSchema.Maximum) {
+			return []FieldError{{fieldSchema.Name, fmt.Sprintf("must be between %d and %d", fieldSchema.Minimum, fieldSchema.Maximum)}}
+		}
+
+	case TypeBoolean:
+		var boolean bool
+		if json.Unmarshal(value, &boolean) != nil {
+			return []FieldError{{fieldSchema.Name, "must be a boolean"}}
+		}
+	}
+	return nil
+}
+
+func validateString(name string, fieldSchema FieldSchema, str string) []FieldError {
+	length := utf8.RuneCountInString(str)
+	if fieldSchema.Required && length == 0 {
+		return []FieldError{{name, "must be a non-empty string"}}
+	}
+	if length < fieldSchema.MinLength {
+		return []FieldError{{name, fmt.Sprintf("must be at least %d characters", fieldSchema.MinLength)}}
+	}
+	if fieldSchema.MaxLength > 0 && length > fieldSchema.MaxLength {
+		return []FieldError{{name, fmt.Sprintf("must be at most %d characters", fieldSchema.MaxLength)}}
+	}
+	if pattern, found := formatPatterns[fieldSchema.Format]; found && length > 0 && !pattern.MatchString(str) {
+		return []FieldError{{name, formatDescriptions[fieldSchema.Format]}}
+	}
+	if fieldSchema.Format == FormatCID && length > 0 {
+		if _, err := ParseCID(str); err != nil {
+			return []FieldError{{name, "is not a valid CID - " + err.Error()}}
+		}
+	}
+	return nil
+}
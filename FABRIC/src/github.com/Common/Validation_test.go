@@ -0,0 +1,99 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+// a hash id and a raw CID of the same content
+const (
+	testHashID = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	testCID    = "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e"
+)
+
+type embeddedTestRequest struct {
+	SubjectID string `json:"SubjectID" validate:"maxlength=8,format=id"`
+}
+
+type validationTestRequest struct {
+	embeddedTestRequest
+	AnswerHashID string   `json:"AnswerHashID" validate:"format=hash"`
+	AnswerCID    string   `json:"AnswerCID" metadata:",optional" validate:"maxlength=128,format=cid"`
+	Count        int      `json:"Count" metadata:",optional" validate:"minimum=1,maximum=10"`
+	Accept       bool     `json:"Accept" metadata:",optional"`
+	TechNames    []string `json:"TechNames" metadata:",optional" validate:"maxlength=16,format=tech"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf("Test", reflect.TypeOf(validationTestRequest{}))
+	expected := []FieldSchema{
+		{Name: "SubjectID", Type: TypeString, Required: true, MaxLength: 8, Format: FormatID},
+		{Name: "AnswerHashID", Type: TypeString, Required: true, Format: FormatHashID},
+		{Name: "AnswerCID", Type: TypeString, MaxLength: 128, Format: FormatCID},
+		{Name: "Count", Type: TypeInteger, Minimum: 1, Maximum: 10},
+		{Name: "Accept", Type: TypeBoolean},
+		{Name: "TechNames", Type: TypeStringArray, MaxLength: 16, Format: FormatTech},
+	}
+	if schema.Function != "Test" || !reflect.DeepEqual(schema.Fields, expected) {
+		t.Errorf("SchemaOf = %+v, want the fields %+v", schema, expected)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	schema := SchemaOf("Test", reflect.TypeOf(validationTestRequest{}))
+	tests := []struct {
+		name    string
+		request string
+		fields  []string
+	}{
+		{name: "required fields only", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `"}`},
+		{name: "every field", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","AnswerCID":"` + testCID + `","Count":10,"Accept":true,"TechNames":["go","c++"]}`},
+		{name: "null optional field", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","AnswerCID":null}`},
+		{name: "not an object", request: `[]`, fields: []string{""}},
+		{name: "missing required fields", request: `{}`, fields: []string{"SubjectID", "AnswerHashID"}},
+		{name: "empty string", request: `{"SubjectID":"","AnswerHashID":"` + testHashID + `"}`, fields: []string{"SubjectID"}},
+		{name: "too long", request: `{"SubjectID":"student123","AnswerHashID":"` + testHashID + `"}`, fields: []string{"SubjectID"}},
+		{name: "wrong format", request: `{"SubjectID":"-s1","AnswerHashID":"xyz"}`, fields: []string{"SubjectID", "AnswerHashID"}},
+		{name: "malformed CID", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","AnswerCID":"bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n"}`, fields: []string{"AnswerCID"}},
+		{name: "integer out of range", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","Count":11}`, fields: []string{"Count"}},
+		{name: "integer as a string", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","Count":"1"}`, fields: []string{"Count"}},
+		{name: "fractional integer", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","Count":1.5}`, fields: []string{"Count"}},
+		{name: "boolean as a string", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","Accept":"true"}`, fields: []string{"Accept"}},
+		{name: "array element", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","TechNames":["go","-go"]}`, fields: []string{"TechNames[1]"}},
+		{name: "unknown fields sorted", request: `{"SubjectID":"s1","AnswerHashID":"` + testHashID + `","Zeta":1,"Alpha":2}`, fields: []string{"Alpha", "Zeta"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateRequest(test.request, schema)
+			if len(test.fields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateRequest(%s) failed - %s", test.request, err)
+				}
+				return
+			}
+			chaincodeError, ok := err.(*ChaincodeError)
+			if !ok || chaincodeError.Code != ErrValidationFailed {
+				t.Fatalf("ValidateRequest(%s) = %v, want a %s envelope", test.request, err, ErrValidationFailed)
+			}
+			var fields []string
+			for _, fieldError := range chaincodeError.Details.([]FieldError) {
+				fields = append(fields, fieldError.Field)
+			}
+			if !reflect.DeepEqual(fields, test.fields) {
+				t.Errorf("ValidateRequest(%s) rejected the fields %q, want %q", test.request, fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestSchemaOfInvalidConstraint(t *testing.T) {
+	type invalidTestRequest struct {
+		PageSize int `json:"PageSize" validate:"minimum=1,maximum=MaxPageSize"`
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("SchemaOf accepted the constraint maximum=MaxPageSize")
+		}
+	}()
+	SchemaOf("Test", reflect.TypeOf(invalidTestRequest{}))
+}
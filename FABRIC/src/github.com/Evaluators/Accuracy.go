@@ -0,0 +1,91 @@
+package main
+
+// ============================================================================================================================
+// Evaluator Accuracy - the Answer chaincode scores every thumbs up of an evaluator against the outcome of the answer,
+// right away for the gold-standard answers an admin seeded with a known outcome and once the outcome is final for the
+// others, the score is kept on the evaluator record
+// ============================================================================================================================
+
+// outcome of a scored vote, REVISED turns a vote scored INCORRECT into a correct one once an appeal overturned the outcome
+const (
+	VoteOutcomeCorrect   = "CORRECT"
+	VoteOutcomeIncorrect = "INCORRECT"
+	VoteOutcomeRevised   = "REVISED"
+)
+
+// EventAccuracyChanged is raised by RecordEvaluationOutcome, its payload is an AccuracyChangedEvent
+const EventAccuracyChanged = "AccuracyChanged"
+
+// EvaluatorAccuracy the votes of the evaluator scored so far, the gold-standard ones counted in them as well
+type EvaluatorAccuracy struct {
+	ScoredVotes      int    `json:"ScoredVotes"`
+	CorrectVotes     int    `json:"CorrectVotes"`
+	GoldVotes        int    `json:"GoldVotes"`
+	CorrectGoldVotes int    `json:"CorrectGoldVotes"`
+	AccuracyPercent  int    `json:"AccuracyPercent"`
+	ScoredOn         string `json:"ScoredOn,omitempty"`
+}
+
+// AccuracyChangedEvent payload of AccuracyChanged
+type AccuracyChangedEvent struct {
+	EvaluatorID     string `json:"EvaluatorID"`
+	AnswerHashID    string `json:"AnswerHashID"`
+	Outcome         string `json:"Outcome"`
+	ScoredVotes     int    `json:"ScoredVotes"`
+	CorrectVotes    int    `json:"CorrectVotes"`
+	AccuracyPercent int    `json:"AccuracyPercent"`
+	ScoredOn        string `json:"ScoredOn"`
+}
+
+// EvaluatorChanges the accuracy and reputation changes a function settling the outcome of an answer made to the
+// evaluator, returned so the Answer chaincode raises them as well since the events of a called chaincode never reach
+// the client
+type EvaluatorChanges struct {
+	AccuracyChanged   *AccuracyChangedEvent   `json:"AccuracyChanged,omitempty"`
+	ReputationChanged *ReputationChangedEvent `json:"ReputationChanged,omitempty"`
+}
+
+// RecordEvaluationOutcomeRequest request object of RecordEvaluationOutcome, Gold when the answer is a gold-standard one,
+// RepuDelta the reputation the evaluator gains or loses in TechName with the outcome
+type RecordEvaluationOutcomeRequest struct {
+	EvaluatorID  string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	AnswerHashID string `json:"AnswerHashID" validate:"format=hash"`
+	Outcome      string `json:"Outcome" validate:"maxlength=16"`
+	Gold         bool   `json:"Gold" metadata:",optional"`
+	TechName     string `json:"TechName" metadata:",optional" validate:"maxlength=64,format=tech"`
+	RepuDelta    int    `json:"RepuDelta" metadata:",optional" validate:"minimum=-10000,maximum=10000"`
+}
+
+// Record scores one vote and recomputes the accuracy
+func (accuracy *EvaluatorAccuracy) Record(outcome string, gold bool, scoredOn string) {
+	switch outcome {
+	case VoteOutcomeCorrect:
+		accuracy.ScoredVotes++
+		accuracy.CorrectVotes++
+		if gold {
+			accuracy.GoldVotes++
+			accuracy.CorrectGoldVotes++
+		}
+	case VoteOutcomeIncorrect:
+		accuracy.ScoredVotes++
+		if gold {
+			accuracy.GoldVotes++
+		}
+	case VoteOutcomeRevised:
+		if accuracy.CorrectVotes < accuracy.ScoredVotes {
+			accuracy.CorrectVotes++
+		}
+	}
+	if accuracy.ScoredVotes > 0 {
+		accuracy.AccuracyPercent = accuracy.CorrectVotes * 100 / accuracy.ScoredVotes
+	}
+	accuracy.ScoredOn = scoredOn
+}
+
+// scoreVote scores the evaluator's thumbs up of the answer, it leaves writing the evaluator and raising the returned
+// event to the caller
+func scoreVote(evaluator *Evaluator, answerHashID string, outcome string, gold bool, scoredOn string) *AccuracyChangedEvent {
+	evaluator.Accuracy.Record(outcome, gold, scoredOn)
+	return &AccuracyChangedEvent{evaluator.EvaluatorID, answerHashID, outcome, evaluator.Accuracy.ScoredVotes, evaluator.Accuracy.CorrectVotes,
+		evaluator.Accuracy.AccuracyPercent, scoredOn}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/Common"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+// testClientIdentity a client holding the given enrollment certificate attributes
+type testClientIdentity struct {
+	attributes map[string]string
+}
+
+func (identity testClientIdentity) GetID() (string, error)    { return "user1", nil }
+func (identity testClientIdentity) GetMSPID() (string, error) { return "Org1MSP", nil }
+func (identity testClientIdentity) GetAttributeValue(name string) (string, bool, error) {
+	value, found := identity.attributes[name]
+	return value, found, nil
+}
+func (identity testClientIdentity) AssertAttributeValue(name, value string) error {
+	if identity.attributes[name] != value {
+		return errors.New("attribute " + name + " does not have the value " + value)
+	}
+	return nil
+}
+func (identity testClientIdentity) GetX509Certificate() (*x509.Certificate, error) { return nil, nil }
+
+func TestNewEvaluatorChaincode(t *testing.T) {
+	_, err := newEvaluatorChaincode()
+	if err != nil {
+		t.Errorf("newEvaluatorChaincode failed - %s", err)
+	}
+	// panics on a constraint that is not a number
+	common.RequestSchemas(new(EvaluatorChaincode))
+}
+
+func TestBumpUpEvaluatorRepuAccess(t *testing.T) {
+	stub := shimtest.NewMockStub("Evaluators", nil)
+	stub.MockTransactionStart("bump")
+	defer stub.MockTransactionEnd("bump")
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		code       string
+	}{
+		{name: "client", attributes: map[string]string{}, code: common.ErrForbidden},
+		{name: "client claiming to be an admin", attributes: map[string]string{common.AdminAttribute: "false"}, code: common.ErrForbidden},
+		// passes the check and fails on the evaluator that does not exist
+		{name: "admin", attributes: map[string]string{common.AdminAttribute: "true"}, code: common.ErrNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := &TransactionContext{}
+			ctx.SetStub(stub)
+			ctx.SetClientIdentity(testClientIdentity{attributes: test.attributes})
+			req := BumpUpEvaluatorRepuRequest{EvaluatorID: "e1", TechName: "go", UpCount: 1000, TechsChaincode: "techs"}
+			err := new(EvaluatorChaincode).BumpUpEvaluatorRepu(ctx, req)
+			chaincodeError, ok := err.(*common.ChaincodeError)
+			if !ok || chaincodeError.Code != test.code {
+				t.Errorf("BumpUpEvaluatorRepu by %v = %v, want a %s envelope", test.attributes, err, test.code)
+			}
+		})
+	}
+}
@@ -1,20 +1,21 @@
 package main
 
 import (
-	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strconv"
-	"time"
+	"sort"
+	"strings"
 
-	"github.com/hyperledger/fabric/core/chaincode/shim"
-	pb "github.com/hyperledger/fabric/protos/peer"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/Common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
-// EvaluatorChaincode example simple Chaincode implementation
+// EvaluatorChaincode contract for registering evaluators and tracking their reputation
 type EvaluatorChaincode struct {
+	contractapi.Contract
 }
 
 // ============================================================================================================================
@@ -32,570 +33,1268 @@ type TechRepu struct {
 // ============================================================================================================================
 
 type Evaluator struct {
-	EvaluatorID        string     `json:"EvaluatorID"`
-	EvaluatorSecret    string     `json:"EvaluatorSecret"`
-	EvaluatedAnswers   []string   `json:"EvaluatedAnswers"` // only should container TechIDs so we can perform array ops on it with efficiency
-	EvaluatorTechRepus []TechRepu `json:"EvaluatorTechRepos"`
-	CreatedON          string     `json:"createdOn"`
+	EvaluatorID          string       `json:"EvaluatorID"`
+	EvaluatorSecret      string       `json:"EvaluatorSecret"`
+	EvaluatedAnswers     []string     `json:"EvaluatedAnswers"` // only should container TechIDs so we can perform array ops on it with efficiency
+	EvaluatorTechRepus   []TechRepu   `json:"EvaluatorTechRepos"`
+	CreatedON            string       `json:"createdOn"`
+	SecretRotatedOn      string       `json:"SecretRotatedOn,omitempty"`
+	SecretRotationReason string       `json:"SecretRotationReason,omitempty"`
+	SecretReset          *SecretReset `json:"SecretReset,omitempty"`
+	// the id of the enrollment certificate that registered the evaluator, accounts registered by the same enrollment
+	// are linked and never evaluate each other
+	EnrollmentID string `json:"EnrollmentID,omitempty"`
+	// the ids of the students and questioners the evaluator declared a conflict of interest with
+	DeclaredConflicts []string `json:"DeclaredConflicts,omitempty"`
+	// the thumbs up of the evaluator scored against the outcome of their answers, see Accuracy.go
+	Accuracy EvaluatorAccuracy `json:"Accuracy"`
+	common.AuthState
+}
+
+// MaxTechNames the most techs GetEvaluatorsByTechs looks up at once, a tech and its ancestors
+const MaxTechNames = 17
+
+// MaxDeclaredConflicts the most conflicts of interest an evaluator can declare
+const MaxDeclaredConflicts = 100
+
+// techIndexObjectType the evaluators are indexed under a composite key of each tech they hold and their id, so
+// GetEvaluatorsByTechs reads the evaluators of a tech without reading every evaluator
+const techIndexObjectType = "tech~evaluator"
+
+// SecretReset a pending admin issued reset of the secret, only the sha256 of the one-time reset token is stored
+type SecretReset struct {
+	TokenHash string `json:"TokenHash"`
+	IssuedBy  string `json:"IssuedBy"`
+	IssuedOn  string `json:"IssuedOn"`
+	ExpiresOn string `json:"ExpiresOn"`
+}
+
+// reasons recorded on the evaluator whenever the secret changes
+const (
+	SecretRotationReasonRotated = "ROTATED"
+	SecretRotationReasonReset   = "ADMIN_RESET"
+)
+
+// EvaluatorQueryResult structure used for handling result of rich queries
+type EvaluatorQueryResult struct {
+	Key    string     `json:"Key"`
+	Record *Evaluator `json:"Record"`
+}
+
+// AuditHistory one entry of the key history of an evaluator
+type AuditHistory struct {
+	TxId  string    `json:"txId"`
+	Value Evaluator `json:"value"`
 }
 
 // ============================================================================================================================
-// Main
+// Event Definitions - the events raised by the evaluator functions and their payloads, see Common/Events.go
 // ============================================================================================================================
-func main() {
-	err := shim.Start(new(EvaluatorChaincode))
-	if err != nil {
-		fmt.Printf("Error starting Simple chaincode - %s", err)
-	}
+
+// names of the events raised by the evaluator functions
+const (
+	EventEvaluatorRegistered = "EvaluatorRegistered"
+	EventReputationChanged   = "ReputationChanged"
+	EventSecretRotated       = "SecretRotated"
+	EventSecretResetIssued   = "SecretResetIssued"
+	EventTechAdded           = "TechAdded"
+	EventTechRemoved         = "TechRemoved"
+	EventConflictDeclared    = "ConflictDeclared"
+)
+
+// EvaluatorRegisteredEvent payload of EvaluatorRegistered, raised by AddAnEvaluator, never carries the secret
+type EvaluatorRegisteredEvent struct {
+	EvaluatorID     string `json:"EvaluatorID"`
+	InitialTechName string `json:"InitialTechName"`
+	AttainedRepu    int    `json:"AttainedRepu"`
+	CreatedOn       string `json:"CreatedOn"`
+}
+
+// ReputationChangedEvent payload of ReputationChanged, raised by BumpUpEvaluatorRepu with SubjectType EVALUATOR
+type ReputationChangedEvent struct {
+	SubjectType  string `json:"SubjectType"`
+	SubjectID    string `json:"SubjectID"`
+	TechName     string `json:"TechName"`
+	PreviousRepu int    `json:"PreviousRepu"`
+	AttainedRepu int    `json:"AttainedRepu"`
+	ChangedOn    string `json:"ChangedOn"`
+}
+
+// SecretRotatedEvent payload of SecretRotated, raised by RotateEvaluatorSecret and ResetEvaluatorSecret with SubjectType EVALUATOR
+type SecretRotatedEvent struct {
+	SubjectType string `json:"SubjectType"`
+	SubjectID   string `json:"SubjectID"`
+	Reason      string `json:"Reason"`
+	RotatedOn   string `json:"RotatedOn"`
+}
+
+// SecretResetIssuedEvent payload of SecretResetIssued, raised by IssueEvaluatorSecretReset, never carries the token
+type SecretResetIssuedEvent struct {
+	SubjectType string `json:"SubjectType"`
+	SubjectID   string `json:"SubjectID"`
+	IssuedOn    string `json:"IssuedOn"`
+	ExpiresOn   string `json:"ExpiresOn"`
+}
+
+// TechAddedEvent payload of TechAdded, raised by AddEvaluatorTech and by BumpUpEvaluatorRepu when it creates the tech
+type TechAddedEvent struct {
+	SubjectType  string `json:"SubjectType"`
+	SubjectID    string `json:"SubjectID"`
+	TechName     string `json:"TechName"`
+	AttainedRepu int    `json:"AttainedRepu"`
+	AddedOn      string `json:"AddedOn"`
+}
+
+// TechRemovedEvent payload of TechRemoved, raised by RemoveTech with the reputation that was dropped
+type TechRemovedEvent struct {
+	SubjectType  string `json:"SubjectType"`
+	SubjectID    string `json:"SubjectID"`
+	TechName     string `json:"TechName"`
+	AttainedRepu int    `json:"AttainedRepu"`
+	RemovedOn    string `json:"RemovedOn"`
+}
+
+// ConflictDeclaredEvent payload of ConflictDeclared, raised by DeclareConflict
+type ConflictDeclaredEvent struct {
+	EvaluatorID  string `json:"EvaluatorID"`
+	ConflictWith string `json:"ConflictWith"`
+	DeclaredOn   string `json:"DeclaredOn"`
 }
 
 // ============================================================================================================================
-// Init - initialize the chaincode
+// Request Objects - the JSON request accepted by each function and its declared schema
 // ============================================================================================================================
-func (t *EvaluatorChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
-	fmt.Println("Evaluator Store Channel Is Starting Up")
-	funcName, args := stub.GetFunctionAndParameters()
-	var err error
-	txId := stub.GetTxID()
 
-	fmt.Println("  Init() is running")
-	fmt.Println("  Transaction ID: ", txId)
-	fmt.Println("  GetFunctionAndParameters() function: ", funcName)
-	fmt.Println("  GetFunctionAndParameters() args count: ", len(args))
-	fmt.Println("  GetFunctionAndParameters() args found: ", args)
+// InitLedgerRequest request object of InitLedger
+type InitLedgerRequest struct {
+	Key   string `json:"Key" validate:"maxlength=64"`
+	Value string `json:"Value" validate:"maxlength=256"`
+}
 
-	// expecting 1 arg for instantiate or upgrade
-	if len(args) == 2 {
-		fmt.Println("  GetFunctionAndParameters() : Number of arguments", len(args))
-	}
-	// this is a very simple test. let's write to the ledger and error out on any errors
-	// it's handy to read this right away to verify network is healthy if it wrote the correct value
-	err = stub.PutState(args[0], []byte(args[1]))
-	if err != nil {
-		return shim.Error(err.Error()) //self-test fail
-	}
+// AddAnEvaluatorRequest request object of AddAnEvaluator
+type AddAnEvaluatorRequest struct {
+	EvaluatorID     string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	InitialTechName string `json:"InitialTechName" validate:"maxlength=64,format=tech"`
+	TechsChaincode  string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+}
 
-	fmt.Println("Ready for action") //self-test pass
-	return shim.Success(nil)
+// BumpUpEvaluatorRepuRequest request object of BumpUpEvaluatorRepu
+type BumpUpEvaluatorRepuRequest struct {
+	EvaluatorID    string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	TechName       string `json:"TechName" validate:"maxlength=64,format=tech"`
+	UpCount        int    `json:"UpCount" validate:"minimum=1,maximum=10000"`
+	TechsChaincode string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+}
+
+// AdjustEvaluatorRepuRequest request object of AdjustEvaluatorRepu, TechName is the tech the evaluator was qualified
+// by for the answer and Delta the reputation it gains, or loses when negative
+type AdjustEvaluatorRepuRequest struct {
+	EvaluatorID  string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	TechName     string `json:"TechName" validate:"maxlength=64,format=tech"`
+	Delta        int    `json:"Delta" validate:"minimum=-10000,maximum=10000"`
+	AnswerHashID string `json:"AnswerHashID" validate:"format=hash"`
+}
+
+// EvaluatorIDRequest request object of the functions reading a single evaluator
+type EvaluatorIDRequest struct {
+	EvaluatorID string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+}
+
+// UpdateTheEvaluatedAnswersRequest request object of UpdateTheEvaluatedAnswers, Outcome and Gold score the thumbs up and
+// RepuDelta adjusts the reputation in TechName at once when the answer's outcome is known, see RecordEvaluationOutcome
+type UpdateTheEvaluatedAnswersRequest struct {
+	EvaluatorID  string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	AnswerHashID string `json:"AnswerHashID" validate:"format=hash"`
+	Outcome      string `json:"Outcome" metadata:",optional" validate:"maxlength=16"`
+	Gold         bool   `json:"Gold" metadata:",optional"`
+	TechName     string `json:"TechName" metadata:",optional" validate:"maxlength=64,format=tech"`
+	RepuDelta    int    `json:"RepuDelta" metadata:",optional" validate:"minimum=-10000,maximum=10000"`
+}
+
+// EvaluatorTechAuthRequest request object of the functions the evaluator manages its techs with, the secret travels
+// in the transient map
+type EvaluatorTechAuthRequest struct {
+	EvaluatorID    string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	TechName       string `json:"TechName" validate:"maxlength=64,format=tech"`
+	TechsChaincode string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+}
+
+// EvaluatorsByTechsRequest request object of GetEvaluatorsByTechs
+type EvaluatorsByTechsRequest struct {
+	TechNames []string `json:"TechNames" validate:"maxlength=64,format=tech"`
+}
+
+// DeclareConflictRequest request object of DeclareConflict
+type DeclareConflictRequest struct {
+	EvaluatorID  string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	ConflictWith string `json:"ConflictWith" validate:"maxlength=64,format=id"`
+}
+
+// IssueEvaluatorSecretResetRequest request object of IssueEvaluatorSecretReset, the admin hands the reset token to the evaluator
+// out of band and only sends its sha256
+type IssueEvaluatorSecretResetRequest struct {
+	EvaluatorID      string `json:"EvaluatorID" validate:"maxlength=64,format=id"`
+	ResetTokenHash   string `json:"ResetTokenHash" validate:"format=hash"`
+	ExpiresInMinutes int    `json:"ExpiresInMinutes" validate:"minimum=1,maximum=10080"`
 }
 
 // ============================================================================================================================
-// Invoke - Our entry point for Invocations
+// Transaction Context - helpers available to every evaluator transaction
 // ============================================================================================================================
-func (t *EvaluatorChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
-	function, args := stub.GetFunctionAndParameters()
-	fmt.Println(" ")
-	fmt.Println("starting invoke, for - " + function)
 
-	// Handle different functions
-	if function == "addAnEvaluator" { //create a new marble
-		return addAnEvaluator(stub, args)
-	} else if function == "bumpUpEvaluatorRepu" { //create a new marble
-		return bumpUpEvaluatorRepu(stub, args)
-	} else if function == "getEvaluatorById" {
-		return getEvaluatorById(stub, args)
-	} else if function == "queryEvaluatorById" {
-		return queryEvaluatorById(stub, args)
-	} else if function == "updateTheEvaluatedAnswers" {
-		return updateTheEvaluatedAnswers(stub, args)
+// TransactionContextInterface is the context handed to every EvaluatorChaincode function
+type TransactionContextInterface interface {
+	common.TransactionContextInterface
+	GetEvaluator(evaluatorID string) (*Evaluator, error)
+	PutEvaluator(evaluator *Evaluator) error
+	PutTechIndex(techName string, evaluatorID string) error
+	DelTechIndex(techName string, evaluatorID string) error
+	GetEvaluatorIDsByTech(techName string) ([]string, error)
+}
+
+// TransactionContext implementation of TransactionContextInterface
+type TransactionContext struct {
+	common.TransactionContext
+}
+
+// GetEvaluator reads an evaluator from the world state, nil if it does not exist
+func (ctx *TransactionContext) GetEvaluator(evaluatorID string) (*Evaluator, error) {
+	evaluatorAsBytes, err := ctx.GetStub().GetState(evaluatorID)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding evaluator for - %s", evaluatorID)
+	}
+	if evaluatorAsBytes == nil {
+		return nil, nil
 	}
 
-	// error out
-	fmt.Println("Received unknown invoke function name - " + function)
-	return shim.Error("Received unknown invoke function name - '" + function + "'")
+	eval, err := JSONtoEval(evaluatorAsBytes)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to convert jsonToDoc for %s", evaluatorID)
+	}
+	return &eval, nil
 }
 
-// ============================================================================================================================
-// Query - legacy function
-// ============================================================================================================================
-func (t *EvaluatorChaincode) Query(stub shim.ChaincodeStubInterface) pb.Response {
-	return shim.Error("Unknown supported call - Query()")
+// PutEvaluator writes an evaluator to the world state keyed by the evaluator id
+func (ctx *TransactionContext) PutEvaluator(evaluator *Evaluator) error {
+	buff, err := EvaltoJSON(*evaluator)
+	if err != nil {
+		return common.InternalError(err, "unable to convert evaluator to json")
+	}
+	err = ctx.GetStub().PutState(evaluator.EvaluatorID, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write evaluator - %s", evaluator.EvaluatorID)
+	}
+	return nil
 }
 
-func read_everything(stub shim.ChaincodeStubInterface) pb.Response {
-	type Everything struct {
-		Evaluators []Evaluator `json:"evaluators"`
+// PutTechIndex indexes the evaluator against a tech it holds
+func (ctx *TransactionContext) PutTechIndex(techName string, evaluatorID string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(techIndexObjectType, []string{techName, evaluatorID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the tech index key for %s", evaluatorID)
 	}
-	var everything Everything
+	// the value is never read, a key needs one to be stored
+	err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+	if err != nil {
+		return common.InternalError(err, "unable to index the tech %s of - %s", techName, evaluatorID)
+	}
+	return nil
+}
+
+// DelTechIndex drops the evaluator from the index of a tech it no longer holds
+func (ctx *TransactionContext) DelTechIndex(techName string, evaluatorID string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(techIndexObjectType, []string{techName, evaluatorID})
+	if err != nil {
+		return common.InternalError(err, "unable to create the tech index key for %s", evaluatorID)
+	}
+	err = ctx.GetStub().DelState(indexKey)
+	if err != nil {
+		return common.InternalError(err, "unable to drop the tech %s of - %s from the index", techName, evaluatorID)
+	}
+	return nil
+}
 
-	// ---- Get All Evaluators ---- //
-	resultsIterator, err := stub.GetStateByRange("m0", "m9999999999999999999")
+// GetEvaluatorIDsByTech the ids of the evaluators indexed against the tech, in the order of their keys
+func (ctx *TransactionContext) GetEvaluatorIDsByTech(techName string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(techIndexObjectType, []string{techName})
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, common.InternalError(err, "unable to read the evaluators of the tech %s", techName)
 	}
 	defer resultsIterator.Close()
 
+	var evaluatorIDs []string
 	for resultsIterator.HasNext() {
-		aKeyValue, err := resultsIterator.Next()
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the evaluators of the tech %s", techName)
+		}
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
 		if err != nil {
-			return shim.Error(err.Error())
+			return nil, common.InternalError(err, "unable to split the tech index key %s", queryResponse.Key)
 		}
-		queryKeyAsStr := aKeyValue.Key
-		queryValAsBytes := aKeyValue.Value
-		fmt.Println("on evaluator id - ", queryKeyAsStr)
-		var evaluator Evaluator
-		json.Unmarshal(queryValAsBytes, &evaluator)                      //un stringify it aka JSON.parse()
-		everything.Evaluators = append(everything.Evaluators, evaluator) //add this marble to the list
+		if len(keyParts) != 2 {
+			return nil, common.NewError(common.ErrInternal, "the tech index key %s is not a tech and an evaluator", queryResponse.Key)
+		}
+		evaluatorIDs = append(evaluatorIDs, keyParts[1])
 	}
-	fmt.Println("evaluators array - ", everything.Evaluators)
-
-	//change to array of bytes
-	everythingAsBytes, _ := json.Marshal(everything) //convert to array of bytes
-	return shim.Success(everythingAsBytes)
+	return evaluatorIDs, nil
 }
 
 // ============================================================================================================================
-// Get history of asset
+// Main
 // ============================================================================================================================
-func getHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	type AuditHistory struct {
-		TxId  string    `json:"txId"`
-		Value Evaluator `json:"value"`
+func main() {
+	chaincode, err := newEvaluatorChaincode()
+	if err != nil {
+		fmt.Printf("Error creating Evaluator chaincode - %s", err)
+		return
 	}
-	var history []AuditHistory
-	var evaluator Evaluator
 
-	if len(args) != 1 {
-		return shim.Error("Incorrect number of arguments. Expecting 1")
+	err = chaincode.Start()
+	if err != nil {
+		fmt.Printf("Error starting Evaluator chaincode - %s", err)
 	}
+}
 
-	evaluatorId := args[0]
-	fmt.Printf("- start getHistoryForMarble: %s\n", evaluatorId)
+// newEvaluatorChaincode the contract api chaincode of the Evaluator contract, fails when the contract's metadata is rejected
+func newEvaluatorChaincode() (*contractapi.ContractChaincode, error) {
+	evaluatorChaincode := new(EvaluatorChaincode)
+	evaluatorChaincode.TransactionContextHandler = new(TransactionContext)
+	evaluatorChaincode.UnknownTransaction = common.UnknownTransaction
+	evaluatorChaincode.BeforeTransaction = common.BeforeTransaction(evaluatorChaincode)
+	evaluatorChaincode.AfterTransaction = common.AfterTransaction
 
-	// Get History
-	resultsIterator, err := stub.GetHistoryForKey(evaluatorId)
+	chaincode, err := contractapi.NewChaincode(evaluatorChaincode)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, err
 	}
-	defer resultsIterator.Close()
+	chaincode.Info.Title = "Evaluators"
+	chaincode.Info.Version = "2.0.0"
+	return chaincode, nil
+}
 
-	for resultsIterator.HasNext() {
-		historyData, err := resultsIterator.Next()
-		if err != nil {
-			return shim.Error(err.Error())
-		}
+// ============================================================================================================================
+// InitLedger - initialize the chaincode
+// ============================================================================================================================
+func (t *EvaluatorChaincode) InitLedger(ctx TransactionContextInterface, req InitLedgerRequest) error {
+	fmt.Println("Evaluator Store Channel Is Starting Up")
+	fmt.Println("  InitLedger() is running")
+	fmt.Println("  Transaction ID: ", ctx.GetStub().GetTxID())
 
-		var tx AuditHistory
-		tx.TxId = historyData.TxId                    //copy transaction id over
-		json.Unmarshal(historyData.Value, &evaluator) //un stringify it aka JSON.parse()
-		if historyData.Value == nil {                 //marble has been deleted
-			var emptyEvaluator Evaluator
-			tx.Value = emptyEvaluator //copy nil marble
-		} else {
-			json.Unmarshal(historyData.Value, &evaluator) //un stringify it aka JSON.parse()
-			tx.Value = evaluator                          //copy marble over
-		}
-		history = append(history, tx) //add this tx to the list
+	// this is a very simple test. let's write to the ledger and error out on any errors
+	// it's handy to read this right away to verify network is healthy if it wrote the correct value
+	err := ctx.GetStub().PutState(req.Key, []byte(req.Value))
+	if err != nil {
+		return common.InternalError(err, "self-test write failed") //self-test fail
 	}
-	fmt.Printf("- getHistoryForEvaluator returning:\n%s", history)
 
-	//change to array of bytes
-	historyAsBytes, _ := json.Marshal(history) //convert to array of bytes
-	return shim.Success(historyAsBytes)
+	fmt.Println("Ready for action") //self-test pass
+	return nil
 }
 
-// ========================================================
-// Input Sanitation - dumb input checking, look for empty strings
-// ========================================================
-func sanitize_arguments(strs []string) error {
-	for i, val := range strs {
-		if len(val) <= 0 {
-			return errors.New("Argument " + strconv.Itoa(i) + " must be a non-empty string")
-		}
-		// if len(val) > 32 {
-		// 	return errors.New("Argument " + strconv.Itoa(i) + " must be <= 32 characters")
-		// }
+// AddAnEvaluator registers a new evaluator with an initial tech reputation
+func (t *EvaluatorChaincode) AddAnEvaluator(ctx TransactionContextInterface, req AddAnEvaluatorRequest) error {
+	fmt.Println("starting addAnEvaluator")
+
+	evaluatorID := req.EvaluatorID
+	evaluatorSecret, err := common.GetTransientSecret(ctx, "AddAnEvaluator", "EvaluatorSecret", 8, 72)
+	if err != nil {
+		return err
+	}
+
+	//check if evaluator id already exists
+	existingEvaluator, err := ctx.GetEvaluator(evaluatorID)
+	if err != nil {
+		return err
+	}
+	if existingEvaluator != nil {
+		return common.NewError(common.ErrAlreadyExists, "This evaluator already exists - %s", evaluatorID)
+	}
+
+	createdOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
 	}
+	enrollmentID, err := common.GetClientID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tech, err := common.ResolveTech(ctx, req.TechsChaincode, req.InitialTechName)
+	if err != nil {
+		return err
+	}
+	evaluatorTechRepuObject := CreateEvaluatorTechRepuObject(tech.TechID, createdOn)
+
+	evaluatorObject := CreateEvaluatorObject(evaluatorID, common.HashSecret(ctx, evaluatorSecret), evaluatorTechRepuObject, createdOn, enrollmentID)
+
+	err = ctx.PutEvaluator(&evaluatorObject)
+	if err != nil {
+		return err
+	}
+	err = ctx.PutTechIndex(evaluatorTechRepuObject.UniqueTechName, evaluatorID)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventEvaluatorRegistered, EvaluatorRegisteredEvent{evaluatorID, evaluatorTechRepuObject.UniqueTechName, evaluatorTechRepuObject.AttainedRepu, createdOn})
+
+	fmt.Println("- end addAnEvaluator")
 	return nil
 }
 
-func addAnEvaluator(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
-	fmt.Println("starting addAnEvaluator")
+// BumpUpEvaluatorRepu admin only, adds reputation to an existing tech of the evaluator
+func (t *EvaluatorChaincode) BumpUpEvaluatorRepu(ctx TransactionContextInterface, req BumpUpEvaluatorRepuRequest) error {
+	fmt.Println("starting bumpUpEvaluatorRepu")
 
-	if len(args) != 3 {
-		fmt.Println("initEvaluator(): Incorrect number of arguments. Expecting 3 ")
-		return shim.Error("intEvaluator(): Incorrect number of arguments. Expecting 3 ")
+	err := common.AssertAdmin(ctx, "BumpUpEvaluatorRepu")
+	if err != nil {
+		return err
 	}
 
-	//input sanitation
-	err1 := sanitize_arguments(args)
-	if err1 != nil {
-		return shim.Error("Cannot sanitize arguments")
+	evaluatorID := req.EvaluatorID
+	upCount := req.UpCount
+
+	fmt.Println("bumping up by: ")
+	fmt.Println(upCount)
+
+	dat, err := ctx.GetEvaluator(evaluatorID)
+	if err != nil {
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", evaluatorID)
 	}
 
-	evaluatorInitialTechName := args[0]
-	evaluatorID := args[1]
-	fmt.Println(args)
-	//check if marble id already exists
-	evaluatorAsBytes, err := stub.GetState(evaluatorID)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		return shim.Error("error in finding evaluator for - " + evaluatorID)
+	changedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
 	}
-	if evaluatorAsBytes != nil {
-		fmt.Println("This evaluator already exists - " + evaluatorID)
-		return shim.Error("This evaluator already exists - " + evaluatorID) //all stop a marble by this id exists
+
+	techName, index, err := findEvaluatorTechByName(ctx, req.TechsChaincode, dat, req.TechName)
+	if err != nil {
+		return err
 	}
+	repuChange := ReputationChangedEvent{SubjectType: "EVALUATOR", SubjectID: evaluatorID, TechName: techName, ChangedOn: changedOn}
 
-	evaluatorTechRepuObject, err := CreateEvaluatorTechRepuObject(evaluatorInitialTechName)
+	if index < 0 {
+		config, err := common.GetAccountConfig(ctx)
+		if err != nil {
+			return err
+		}
+		if !config.AutoCreateTechOnBump {
+			return common.NewError(common.ErrNotFound, "tech repu not found for evaluator %s", evaluatorID)
+		}
+		// the tech is created with the reputation it just earned
+		dat.EvaluatorTechRepus = append(dat.EvaluatorTechRepus, TechRepu{UniqueTechName: techName, AttainedRepu: 0, CreatedON: changedOn})
+		index = len(dat.EvaluatorTechRepus) - 1
+		err = ctx.PutTechIndex(techName, evaluatorID)
+		if err != nil {
+			return err
+		}
+		ctx.EmitEvent(EventTechAdded, TechAddedEvent{"EVALUATOR", evaluatorID, techName, upCount, changedOn})
+	}
+	repuChange.PreviousRepu = dat.EvaluatorTechRepus[index].AttainedRepu
+	dat.EvaluatorTechRepus[index].AttainedRepu += upCount
+	repuChange.AttainedRepu = dat.EvaluatorTechRepus[index].AttainedRepu
 
-	evaluatorObject, err := CreateEvaluatorObject(args[1:], evaluatorTechRepuObject)
+	err = ctx.PutEvaluator(dat)
 	if err != nil {
-		errorStr := "initEvaluator() : Failed Cannot create object buffer for write : " + args[0]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return err
 	}
+	ctx.EmitEvent(EventReputationChanged, repuChange)
 
-	fmt.Println(evaluatorObject)
-	buff, err := EvaltoJSON(evaluatorObject)
+	fmt.Println("- end bumpUpEvaluatorRepu")
+	return nil
+}
+
+// AdjustEvaluatorRepu changes the reputation of the evaluator in the tech it evaluated an answer by, required by the
+// Answer chaincode once the outcome of the answer is decided, the reputation never drops below 0 and an evaluator
+// that no longer holds the tech is left as it is, only an admin or a trusted chaincode adjusts it, returns the change
+func (t *EvaluatorChaincode) AdjustEvaluatorRepu(ctx TransactionContextInterface, req AdjustEvaluatorRepuRequest) (*EvaluatorChanges, error) {
+	if req.Delta == 0 {
+		return nil, common.ValidationError("AdjustEvaluatorRepu", []common.FieldError{{Field: "Delta", Message: "must not be 0"}})
+	}
+	err := common.AssertTrustedCaller(ctx, "AdjustEvaluatorRepu")
 	if err != nil {
-		return shim.Error("unable to convert evaluator to json")
+		return nil, err
 	}
 
-	err = stub.PutState(evaluatorID, buff) //store marble with id as key
+	dat, err := ctx.GetEvaluator(req.EvaluatorID)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, err
+	}
+	if dat == nil {
+		return nil, common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", req.EvaluatorID)
+	}
+	changedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	changes := &EvaluatorChanges{ReputationChanged: adjustRepu(dat, req.TechName, req.Delta, changedOn)}
+	if changes.ReputationChanged == nil {
+		return changes, nil
 	}
 
-	fmt.Println("- end addAnEvaluator")
-	return shim.Success(nil)
+	err = ctx.PutEvaluator(dat)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitEvent(EventReputationChanged, changes.ReputationChanged)
+	return changes, nil
 }
 
-// CreateAssetObject creates an asset
-func CreateEvaluatorObject(args []string, techRepu TechRepu) (Evaluator, error) {
-	var myEvaluator Evaluator
+// GetEvaluatorById returns the evaluator stored against the id, required by the Answer chaincode
+func (t *EvaluatorChaincode) GetEvaluatorById(ctx TransactionContextInterface, req EvaluatorIDRequest) (*Evaluator, error) {
+	fmt.Println("sarting the getEvaluatorById() for the evaluator: " + req.EvaluatorID)
 
-	fmt.Println(args)
-	fmt.Println(techRepu)
-	// Check there are 10 Arguments provided as per the the struct
-	if len(args) != 2 {
-		strErr := "CreateEvaluatorObject(): Incorrect number of arguments. Expecting 2 but got " + strconv.Itoa(len(args))
-		fmt.Println(strErr)
-		return myEvaluator, errors.New(strErr)
+	evaluatorID := req.EvaluatorID
+
+	evaluator, err := ctx.GetEvaluator(evaluatorID)
+	if err != nil {
+		return nil, err
+	}
+	if evaluator == nil {
+		return nil, common.NewError(common.ErrNotFound, "Nil data for %s", evaluatorID)
+	}
+
+	return redact(evaluator), nil
+}
+
+// GetEvaluatorsByTechs returns every evaluator holding one of the techs ordered by evaluator id, required by the
+// Answer chaincode to assign evaluators to an answer, it reads the evaluators of each tech from the tech index so
+// the read is validated and only touches the evaluators of the techs
+func (t *EvaluatorChaincode) GetEvaluatorsByTechs(ctx TransactionContextInterface, req EvaluatorsByTechsRequest) ([]*Evaluator, error) {
+	if len(req.TechNames) == 0 || len(req.TechNames) > MaxTechNames {
+		return nil, common.ValidationError("GetEvaluatorsByTechs", []common.FieldError{{Field: "TechNames", Message: fmt.Sprintf("must have between 1 and %d techs", MaxTechNames)}})
 	}
-	dummyTechRepuArray := []TechRepu{}
-	dummyTechRepuArray = append(dummyTechRepuArray, techRepu)
 
-	rawEvalSecret := args[1]
+	var evaluatorIDs []string
+	for _, techName := range req.TechNames {
+		techEvaluatorIDs, err := ctx.GetEvaluatorIDsByTech(techName)
+		if err != nil {
+			return nil, err
+		}
+		for _, evaluatorID := range techEvaluatorIDs {
+			if !stringInSlice(evaluatorID, evaluatorIDs) {
+				evaluatorIDs = append(evaluatorIDs, evaluatorID)
+			}
+		}
+	}
+	sort.Strings(evaluatorIDs)
+
+	results := []*Evaluator{}
+	for _, evaluatorID := range evaluatorIDs {
+		eval, err := ctx.GetEvaluator(evaluatorID)
+		if err != nil {
+			return nil, err
+		}
+		if eval == nil {
+			return nil, common.NewError(common.ErrInternal, "the tech index holds the evaluator %s that does not exist", evaluatorID)
+		}
+		results = append(results, redact(eval))
+	}
+	return results, nil
+}
 
-	hashedpassword, err := HashPassword(rawEvalSecret)
+// IndexEvaluatorTechs admin only, indexes the techs of every evaluator registered before the tech index existed,
+// the evaluators added or changed since are indexed as they are written
+func (t *EvaluatorChaincode) IndexEvaluatorTechs(ctx TransactionContextInterface) error {
+	err := common.AssertAdmin(ctx, "IndexEvaluatorTechs")
 	if err != nil {
+		return err
+	}
 
-		return myEvaluator, errors.New("error in hashing the password")
+	// the range of simple keys holds the evaluators, the config and the index are composite keys outside of it
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return common.InternalError(err, "range query failed")
 	}
-	fmt.Println("hashed password is: " + hashedpassword)
+	defer resultsIterator.Close()
 
-	strArr := []string{}
-	myEvaluator = Evaluator{args[0], hashedpassword, strArr, dummyTechRepuArray, time.Now().Format("20060102150405")}
-	return myEvaluator, nil
+	indexed := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return common.InternalError(err, "unable to iterate the query result")
+		}
+		// the InitLedger self-test value is not an evaluator
+		eval, err := JSONtoEval(queryResponse.Value)
+		if err != nil || eval.EvaluatorID != queryResponse.Key {
+			continue
+		}
+		for _, techRepuData := range eval.EvaluatorTechRepus {
+			err = ctx.PutTechIndex(techRepuData.UniqueTechName, eval.EvaluatorID)
+			if err != nil {
+				return err
+			}
+		}
+		indexed++
+	}
+
+	fmt.Printf("- end indexEvaluatorTechs indexed %d evaluators\n", indexed)
+	return nil
 }
 
-// CreateAssetObject creates an asset
-func CreateEvaluatorTechRepuObject(techName string) (TechRepu, error) {
-	techRepu := TechRepu{techName, 10, time.Now().Format("20060102150405")}
-	return techRepu, nil
+// QueryEvaluatorById very important as it is required by the Answer chaincode to query
+func (t *EvaluatorChaincode) QueryEvaluatorById(ctx TransactionContextInterface, req EvaluatorIDRequest) ([]*EvaluatorQueryResult, error) {
+	queryString := fmt.Sprintf("{\"selector\":{\"EvaluatorID\":\"%s\"}}", req.EvaluatorID)
+
+	return getQueryResultForQueryString(ctx, queryString)
 }
 
-func EvaltoJSON(eval Evaluator) ([]byte, error) {
+// UpdateTheEvaluatedAnswers records that the evaluator evaluated an answer, an answer can only be evaluated once, only
+// called by an admin or through a trusted chaincode, returns the accuracy and reputation changes of a thumbs up scored
+// at once
+func (t *EvaluatorChaincode) UpdateTheEvaluatedAnswers(ctx TransactionContextInterface, req UpdateTheEvaluatedAnswersRequest) (*EvaluatorChanges, error) {
+	fmt.Println("starting updateTheEvaluatedAnswers")
 
-	djson, err := json.Marshal(eval)
+	// only the Answer chaincode knows the answer was evaluated, its outcome, whether it is a gold-standard one and the
+	// reputation the policy of its tech rewards
+	err := common.AssertTrustedCaller(ctx, "UpdateTheEvaluatedAnswers")
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
-	return djson, nil
+
+	evaluatorID := req.EvaluatorID
+	answerHashID := req.AnswerHashID
+
+	dat, err := ctx.GetEvaluator(evaluatorID)
+	if err != nil {
+		return nil, err
+	}
+	if dat == nil {
+		return nil, common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", evaluatorID)
+	}
+
+	if contains(dat.EvaluatedAnswers, answerHashID) {
+		return nil, common.NewError(common.ErrInvalidState, "already evaluated cant evaluate the same answer again - %s", answerHashID)
+	}
+	dat.EvaluatedAnswers = append(dat.EvaluatedAnswers, answerHashID)
+
+	changes := &EvaluatorChanges{}
+	if req.Outcome != "" || req.RepuDelta != 0 {
+		if req.Outcome != "" && req.Outcome != VoteOutcomeCorrect && req.Outcome != VoteOutcomeIncorrect {
+			return nil, common.ValidationError("UpdateTheEvaluatedAnswers", []common.FieldError{{Field: "Outcome", Message: "must be CORRECT or INCORRECT"}})
+		}
+		if req.RepuDelta != 0 && req.TechName == "" {
+			return nil, common.ValidationError("UpdateTheEvaluatedAnswers", []common.FieldError{{Field: "TechName", Message: "is required with a RepuDelta"}})
+		}
+		changedOn, err := ctx.GetTxTime()
+		if err != nil {
+			return nil, err
+		}
+		if req.Outcome != "" {
+			changes.AccuracyChanged = scoreVote(dat, answerHashID, req.Outcome, req.Gold, changedOn)
+		}
+		if req.RepuDelta != 0 {
+			changes.ReputationChanged = adjustRepu(dat, req.TechName, req.RepuDelta, changedOn)
+		}
+	}
+
+	err = ctx.PutEvaluator(dat)
+	if err != nil {
+		return nil, err
+	}
+	if changes.AccuracyChanged != nil {
+		ctx.EmitEvent(EventAccuracyChanged, changes.AccuracyChanged)
+	}
+	if changes.ReputationChanged != nil {
+		ctx.EmitEvent(EventReputationChanged, changes.ReputationChanged)
+	}
+
+	fmt.Println("- end updateTheEvaluatedAnswers")
+	return changes, nil
 }
 
-func JSONtoEval(data []byte) (Evaluator, error) {
+// RecordEvaluationOutcome scores a thumbs up of the evaluator against the outcome of the answer and adjusts its reputation
+// in TechName by RepuDelta as AdjustEvaluatorRepu does, required by the Answer chaincode since an evaluator can only be
+// written once per transaction, only an admin or a trusted chaincode can record it, see AssertTrustedCaller, and the
+// evaluator must have evaluated the answer, returns the changes
+func (t *EvaluatorChaincode) RecordEvaluationOutcome(ctx TransactionContextInterface, req RecordEvaluationOutcomeRequest) (*EvaluatorChanges, error) {
+	if req.Outcome != VoteOutcomeCorrect && req.Outcome != VoteOutcomeIncorrect && req.Outcome != VoteOutcomeRevised {
+		return nil, common.ValidationError("RecordEvaluationOutcome", []common.FieldError{{Field: "Outcome", Message: "must be CORRECT, INCORRECT or REVISED"}})
+	}
+	if req.RepuDelta != 0 && req.TechName == "" {
+		return nil, common.ValidationError("RecordEvaluationOutcome", []common.FieldError{{Field: "TechName", Message: "is required with a RepuDelta"}})
+	}
+	err := common.AssertTrustedCaller(ctx, "RecordEvaluationOutcome")
+	if err != nil {
+		return nil, err
+	}
 
-	eval := Evaluator{}
-	err := json.Unmarshal([]byte(data), &eval)
+	dat, err := ctx.GetEvaluator(req.EvaluatorID)
 	if err != nil {
-		fmt.Println("Unmarshal failed : ", err)
-		return eval, err
+		return nil, err
+	}
+	if dat == nil {
+		return nil, common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", req.EvaluatorID)
+	}
+	if !contains(dat.EvaluatedAnswers, req.AnswerHashID) {
+		return nil, common.NewError(common.ErrInvalidState, "evaluator %s did not evaluate the answer %s", req.EvaluatorID, req.AnswerHashID)
 	}
 
-	return eval, nil
+	scoredOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	changes := &EvaluatorChanges{AccuracyChanged: scoreVote(dat, req.AnswerHashID, req.Outcome, req.Gold, scoredOn)}
+	if req.RepuDelta != 0 {
+		changes.ReputationChanged = adjustRepu(dat, req.TechName, req.RepuDelta, scoredOn)
+	}
+
+	err = ctx.PutEvaluator(dat)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitEvent(EventAccuracyChanged, changes.AccuracyChanged)
+	if changes.ReputationChanged != nil {
+		ctx.EmitEvent(EventReputationChanged, changes.ReputationChanged)
+	}
+	return changes, nil
 }
 
-// query callback representing the query of a chaincode
-func getEvaluatorById(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
-	fmt.Println("sarting the getEvaluatorById() with the args: ")
-	fmt.Println(args)
-	fmt.Println("========================")
-	if len(args) != 1 {
-		return shim.Error("Incorrect number of arguments. Expecting name of the person to query")
+// GetEvaluatorAccuracy returns the accuracy of the evaluator's thumbs up scored so far
+func (t *EvaluatorChaincode) GetEvaluatorAccuracy(ctx TransactionContextInterface, req EvaluatorIDRequest) (*EvaluatorAccuracy, error) {
+	dat, err := ctx.GetEvaluator(req.EvaluatorID)
+	if err != nil {
+		return nil, err
+	}
+	if dat == nil {
+		return nil, common.NewError(common.ErrNotFound, "Nil data for %s", req.EvaluatorID)
 	}
+	return &dat.Accuracy, nil
+}
 
-	evaluatorID := args[0]
+// RotateEvaluatorSecret replaces the secret of the evaluator, the current secret has to be given
+func (t *EvaluatorChaincode) RotateEvaluatorSecret(ctx TransactionContextInterface, req EvaluatorIDRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting rotateEvaluatorSecret")
 
-	// Get the state from the ledger
-	evaluatorbytes, err := stub.GetState(evaluatorID)
+	newSecret, err := common.GetTransientSecret(ctx, "RotateEvaluatorSecret", "NewEvaluatorSecret", 8, 72)
 	if err != nil {
-		jsonResp := "{\"Error\":\"Failed to get state for " + evaluatorID + "\"}"
-		return shim.Error(jsonResp)
+		return nil, err
+	}
+	dat, result, err := getAuthenticatedEvaluator(ctx, "RotateEvaluatorSecret", req.EvaluatorID)
+	if err != nil || !result.Authenticated {
+		return result, err
 	}
 
-	if evaluatorbytes == nil {
-		jsonResp := "{\"Error\":\"Nil data for " + evaluatorID + "\"}"
-		return shim.Error(jsonResp)
+	err = changeEvaluatorSecret(ctx, dat, newSecret, SecretRotationReasonRotated)
+	if err != nil {
+		return nil, err
 	}
 
-	jsonResp := "{\"EvaluatorID\":\"" + evaluatorID + "\",\"data\":\"" + string(evaluatorbytes) + "\"}"
-	fmt.Printf("Query Response:%s\n", jsonResp)
-	return shim.Success(evaluatorbytes)
+	fmt.Println("- end rotateEvaluatorSecret")
+	return result, nil
 }
 
-// very important as it is required by the Answer chaincode to query
-func queryEvaluatorById(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+// IssueEvaluatorSecretReset admin only, lets the evaluator set a new secret without the current one by presenting the reset token
+// before it expires, a new reset replaces a pending one
+func (t *EvaluatorChaincode) IssueEvaluatorSecretReset(ctx TransactionContextInterface, req IssueEvaluatorSecretResetRequest) error {
+	fmt.Println("starting issueEvaluatorSecretReset")
 
-	if len(args) < 1 {
-		return shim.Error("Incorrect number of arguments. Expecting 1")
+	err := common.AssertAdmin(ctx, "IssueEvaluatorSecretReset")
+	if err != nil {
+		return err
 	}
 
-	evaluatorID := args[0]
+	dat, err := ctx.GetEvaluator(req.EvaluatorID)
+	if err != nil {
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", req.EvaluatorID)
+	}
 
-	queryString := fmt.Sprintf("{\"selector\":{\"EvaluatorID\":\"%s\"}}", evaluatorID)
+	issuedBy, err := common.GetClientID(ctx)
+	if err != nil {
+		return err
+	}
+	issuedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	expiresOn, err := common.AddMinutes(issuedOn, req.ExpiresInMinutes)
+	if err != nil {
+		return err
+	}
 
-	queryResults, err := getQueryResultForQueryString(stub, queryString)
+	dat.SecretReset = &SecretReset{strings.ToLower(req.ResetTokenHash), issuedBy, issuedOn, expiresOn}
+	err = ctx.PutEvaluator(dat)
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
 	}
-	return shim.Success(queryResults)
-}
+	ctx.EmitEvent(EventSecretResetIssued, SecretResetIssuedEvent{"EVALUATOR", req.EvaluatorID, issuedOn, expiresOn})
 
-func getQueryResultForQueryString(stub shim.ChaincodeStubInterface, queryString string) ([]byte, error) {
+	fmt.Println("- end issueEvaluatorSecretReset")
+	return nil
+}
 
-	fmt.Printf("- getQueryResultForQueryString queryString:\n%s\n", queryString)
+// ResetEvaluatorSecret sets a new secret with the one-time reset token issued by an admin
+func (t *EvaluatorChaincode) ResetEvaluatorSecret(ctx TransactionContextInterface, req EvaluatorIDRequest) error {
+	fmt.Println("starting resetEvaluatorSecret")
 
-	resultsIterator, err := stub.GetQueryResult(queryString)
+	resetToken, err := common.GetTransientSecret(ctx, "ResetEvaluatorSecret", "ResetToken", 16, 128)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	newSecret, err := common.GetTransientSecret(ctx, "ResetEvaluatorSecret", "NewEvaluatorSecret", 8, 72)
+	if err != nil {
+		return err
 	}
-	defer resultsIterator.Close()
 
-	// buffer is a JSON array containing QueryRecords
-	var buffer bytes.Buffer
-	buffer.WriteString("[")
+	dat, err := ctx.GetEvaluator(req.EvaluatorID)
+	if err != nil {
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", req.EvaluatorID)
+	}
+	if dat.SecretReset == nil {
+		return common.NewError(common.ErrInvalidState, "no secret reset was issued for evaluator %s", req.EvaluatorID)
+	}
 
-	bArrayMemberAlreadyWritten := false
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-		// Add a comma before array members, suppress it for the first array member
-		if bArrayMemberAlreadyWritten == true {
-			buffer.WriteString(",")
-		}
-		buffer.WriteString("{\"Key\":")
-		buffer.WriteString("\"")
-		buffer.WriteString(queryResponse.Key)
-		buffer.WriteString("\"")
+	tokenHash := sha256.Sum256([]byte(resetToken))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(tokenHash[:])), []byte(dat.SecretReset.TokenHash)) != 1 {
+		return common.NewError(common.ErrUnauthorized, "not authorized to reset the secret of evaluator %s", req.EvaluatorID)
+	}
 
-		buffer.WriteString(", \"Record\":")
-		// Record is a JSON object, so we write as-is
-		buffer.WriteString(string(queryResponse.Value))
-		buffer.WriteString("}")
-		bArrayMemberAlreadyWritten = true
+	resetOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	if resetOn > dat.SecretReset.ExpiresOn {
+		return common.NewError(common.ErrInvalidState, "the secret reset of evaluator %s expired on %s", req.EvaluatorID, dat.SecretReset.ExpiresOn)
 	}
-	buffer.WriteString("]")
 
-	fmt.Printf("- getQueryResultForQueryString queryResult:\n%s\n", buffer.String())
+	// the token is consumed with the reset, which also lifts a lock
+	dat.SecretReset = nil
+	dat.AuthState.Reset()
+	err = changeEvaluatorSecret(ctx, dat, newSecret, SecretRotationReasonReset)
+	if err != nil {
+		return err
+	}
 
-	return buffer.Bytes(), nil
+	fmt.Println("- end resetEvaluatorSecret")
+	return nil
 }
 
-func registerEvaluator(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
-	fmt.Println("starting registerEvaluator")
+// AddEvaluatorTech adds another tech to the evaluator, starting with the same reputation as the initial tech
+func (t *EvaluatorChaincode) AddEvaluatorTech(ctx TransactionContextInterface, req EvaluatorTechAuthRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting addEvaluatorTech")
+
+	dat, result, err := getAuthenticatedEvaluator(ctx, "AddEvaluatorTech", req.EvaluatorID)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	techName, index, err := findEvaluatorTechByName(ctx, req.TechsChaincode, dat, req.TechName)
+	if err != nil {
+		return nil, err
+	}
+	if index >= 0 {
+		return nil, common.NewError(common.ErrAlreadyExists, "evaluator %s already has the tech %s", req.EvaluatorID, techName)
+	}
 
-	if len(args) != 3 {
-		return shim.Error("Incorrect number of arguments. Expecting 3")
+	addedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
 	}
+	techRepu := CreateEvaluatorTechRepuObject(techName, addedOn)
+	dat.EvaluatorTechRepus = append(dat.EvaluatorTechRepus, techRepu)
 
-	//input sanitation
-	err = sanitize_arguments(args)
+	err = ctx.PutEvaluator(dat)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, err
 	}
+	err = ctx.PutTechIndex(techRepu.UniqueTechName, req.EvaluatorID)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitEvent(EventTechAdded, TechAddedEvent{"EVALUATOR", req.EvaluatorID, techRepu.UniqueTechName, techRepu.AttainedRepu, addedOn})
+
+	fmt.Println("- end addEvaluatorTech")
+	return result, nil
+}
 
-	evaluatorID := args[0]
-	rawEvaluatorSecret := args[1]
+// RemoveTech removes a tech and its reputation from the evaluator, the last tech cannot be removed
+func (t *EvaluatorChaincode) RemoveTech(ctx TransactionContextInterface, req EvaluatorTechAuthRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting removeTech")
 
-	// hash the evaluator secret and replace the original one
-	args[1], err = HashPassword(rawEvaluatorSecret)
+	dat, result, err := getAuthenticatedEvaluator(ctx, "RemoveTech", req.EvaluatorID)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	_, index, err := findEvaluatorTechByName(ctx, req.TechsChaincode, dat, req.TechName)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		return nil, common.NewError(common.ErrNotFound, "tech repu not found for evaluator %s", req.EvaluatorID)
+	}
+	if len(dat.EvaluatorTechRepus) == 1 {
+		return nil, common.NewError(common.ErrInvalidState, "cannot remove the only tech of evaluator %s", req.EvaluatorID)
+	}
 
-	evaluatorAsBytes, err := stub.GetState(evaluatorID)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Evaluator - " + evaluatorID)
-		return shim.Error("error in finding evaluator for - " + evaluatorID)
+	removedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
 	}
+	removed := dat.EvaluatorTechRepus[index]
+	dat.EvaluatorTechRepus = append(dat.EvaluatorTechRepus[:index], dat.EvaluatorTechRepus[index+1:]...)
 
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Evaluator - " + evaluatorID)
-		return shim.Error("error in finding evaluator for - " + evaluatorID)
+	err = ctx.PutEvaluator(dat)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.DelTechIndex(removed.UniqueTechName, req.EvaluatorID)
+	if err != nil {
+		return nil, err
 	}
+	ctx.EmitEvent(EventTechRemoved, TechRemovedEvent{"EVALUATOR", req.EvaluatorID, removed.UniqueTechName, removed.AttainedRepu, removedOn})
 
-	str := fmt.Sprintf("%s", evaluatorAsBytes)
-	fmt.Println("string is " + str)
+	fmt.Println("- end removeTech")
+	return result, nil
+}
+
+// DeclareConflict records a student or questioner the evaluator has a conflict of interest with, the Answer chaincode refuses
+// the evaluator's thumbs up to the student's answers and questions
+func (t *EvaluatorChaincode) DeclareConflict(ctx TransactionContextInterface, req DeclareConflictRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting declareConflict")
 
-	if evaluatorAsBytes != nil {
-		fmt.Println("This evaluator already exists - " + evaluatorID)
-		return shim.Error("This evaluator already exists - " + evaluatorID) //all stop a marble by this id exists
+	dat, result, err := getAuthenticatedEvaluator(ctx, "DeclareConflict", req.EvaluatorID)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	if req.ConflictWith == req.EvaluatorID || stringInSlice(req.ConflictWith, dat.DeclaredConflicts) {
+		return nil, common.NewError(common.ErrAlreadyExists, "evaluator %s already has a conflict with %s", req.EvaluatorID, req.ConflictWith)
+	}
+	if len(dat.DeclaredConflicts) >= MaxDeclaredConflicts {
+		return nil, common.NewError(common.ErrInvalidState, "evaluator %s already declared %d conflicts", req.EvaluatorID, MaxDeclaredConflicts)
 	}
 
-	techRepuObject, err := CreateEvaluatorTechRepuObject(args[2])
+	declaredOn, err := ctx.GetTxTime()
 	if err != nil {
-		errorStr := "initStudent() : Failed Cannot create object buffer for write : " + args[0]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return nil, err
 	}
+	dat.DeclaredConflicts = append(dat.DeclaredConflicts, req.ConflictWith)
 
-	evaluatorObject, err := CreateEvaluatorObject(args[0:], techRepuObject)
+	err = ctx.PutEvaluator(dat)
 	if err != nil {
-		errorStr := "initStudent() : Failed Cannot create object buffer for write : " + args[0]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return nil, err
 	}
+	ctx.EmitEvent(EventConflictDeclared, ConflictDeclaredEvent{req.EvaluatorID, req.ConflictWith, declaredOn})
+
+	fmt.Println("- end declareConflict")
+	return result, nil
+}
+
+// AuthenticateEvaluator audit transaction checking the secret of the evaluator, submit it as a transaction so a failure is
+// committed and counts towards the lockout, a success clears the failures
+func (t *EvaluatorChaincode) AuthenticateEvaluator(ctx TransactionContextInterface, req EvaluatorIDRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting authenticateEvaluator")
 
-	fmt.Println(evaluatorObject)
-	buff, err := EvaltoJSON(evaluatorObject)
+	secret, err := common.GetTransientSecret(ctx, "AuthenticateEvaluator", "EvaluatorSecret", 0, 72)
 	if err != nil {
-		return shim.Error("unable to convert evaluator to json")
+		return nil, err
 	}
 
-	err = stub.PutState(evaluatorID, buff) //store marble with id as key
+	dat, err := ctx.GetEvaluator(req.EvaluatorID)
+	if err != nil {
+		return nil, err
+	}
+	if dat == nil {
+		return nil, common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", req.EvaluatorID)
+	}
+	result, err := checkEvaluatorSecret(ctx, dat, secret)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, err
 	}
 
-	fmt.Println("- end registerEvaluator")
-	return shim.Success(nil)
+	fmt.Println("- end authenticateEvaluator")
+	return result, nil
 }
 
-func bumpUpEvaluatorRepu(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
-	fmt.Println("starting bumpUpEvaluatorRepu")
+// UnlockEvaluator admin only, forgets the failed authentications of the evaluator and lifts its lock
+func (t *EvaluatorChaincode) UnlockEvaluator(ctx TransactionContextInterface, req EvaluatorIDRequest) error {
+	err := common.AssertAdmin(ctx, "UnlockEvaluator")
+	if err != nil {
+		return err
+	}
 
-	if len(args) != 3 {
-		return shim.Error("Incorrect number of arguments. Expecting 3")
+	dat, err := ctx.GetEvaluator(req.EvaluatorID)
+	if err != nil {
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", req.EvaluatorID)
+	}
+	unlockedBy, err := common.GetClientID(ctx)
+	if err != nil {
+		return err
+	}
+	unlockedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
 	}
 
-	//input sanitation
-	err = sanitize_arguments(args)
+	dat.AuthState.Reset()
+	err = ctx.PutEvaluator(dat)
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
 	}
+	ctx.EmitEvent(common.EventAccountUnlocked, common.AccountUnlockedEvent{SubjectType: "EVALUATOR", SubjectID: req.EvaluatorID, UnlockedBy: unlockedBy, UnlockedOn: unlockedOn})
+	return nil
+}
 
-	evaluatorID := args[0]
-	techName := args[1]
-	upCount, _ := strconv.Atoi(args[2])
+// SetConfig admin only, replaces the lockout settings
+func (t *EvaluatorChaincode) SetConfig(ctx TransactionContextInterface, config common.AccountConfig) error {
+	return common.SetAccountConfig(ctx, config)
+}
 
-	fmt.Println("bumping up by: ")
-	fmt.Println(upCount)
+// GetConfig returns the settings in use, the defaults until an admin set them
+func (t *EvaluatorChaincode) GetConfig(ctx TransactionContextInterface) (*common.AccountConfig, error) {
+	return common.GetAccountConfig(ctx)
+}
+
+// GetEvaluatorHistory every version of the evaluator in its key history, secrets and reset tokens are left out
+func (t *EvaluatorChaincode) GetEvaluatorHistory(ctx TransactionContextInterface, req EvaluatorIDRequest) ([]AuditHistory, error) {
+	return getHistory(ctx, req.EvaluatorID)
+}
 
-	evaluatorAsBytes, err := stub.GetState(evaluatorID)
-	str := fmt.Sprintf("%s", evaluatorAsBytes)
-	fmt.Println("string is " + str)
+// GetRequestSchemas lists the declared request schema of every function
+func (t *EvaluatorChaincode) GetRequestSchemas(ctx TransactionContextInterface) []common.RequestSchema {
+	return common.RequestSchemas(t)
+}
 
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Evaluator - " + evaluatorID)
-		return shim.Error("error in finding evaluator for - " + evaluatorID)
+// getAuthenticatedEvaluator reads the evaluator and checks its secret for a function the evaluator calls itself, a wrong
+// secret is a rejected result the function returns as it is, so the failure is committed with the transaction
+func getAuthenticatedEvaluator(ctx TransactionContextInterface, function string, evaluatorID string) (*Evaluator, *common.AuthenticationResult, error) {
+	secret, err := common.GetTransientSecret(ctx, function, "EvaluatorSecret", 0, 72)
+	if err != nil {
+		return nil, nil, err
+	}
+	dat, err := ctx.GetEvaluator(evaluatorID)
+	if err != nil {
+		return nil, nil, err
 	}
+	if dat == nil {
+		return nil, nil, common.NewError(common.ErrNotFound, "error in finding evaluator for - %s", evaluatorID)
+	}
+	result, err := checkEvaluatorSecret(ctx, dat, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dat, result, nil
+}
 
-	dat, err := JSONtoEval(evaluatorAsBytes)
+// checkEvaluatorSecret checks the secret of the evaluator once it is sure the evaluator is not locked, a success forgets
+// the failures and a wrong secret is counted, either is written to the world state with the transaction
+func checkEvaluatorSecret(ctx TransactionContextInterface, dat *Evaluator, secret string) (*common.AuthenticationResult, error) {
+	now, err := ctx.GetTxTime()
 	if err != nil {
-		return shim.Error("unable to convert jsonToDoc for" + evaluatorID)
+		return nil, err
+	}
+	if dat.IsLocked(now) {
+		return nil, common.LockedError("evaluator", dat.EvaluatorID, dat.LockedUntil)
 	}
 
-	flag := false
+	if common.CheckSecret(secret, dat.EvaluatorSecret) {
+		// only write when there are failures to forget
+		if dat.AuthState != (common.AuthState{}) {
+			dat.AuthState.Reset()
+			err = ctx.PutEvaluator(dat)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &common.AuthenticationResult{Authenticated: true}, nil
+	}
+
+	err = common.RecordFailedAuthentication(ctx, &dat.AuthState, "EVALUATOR", dat.EvaluatorID, now)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.PutEvaluator(dat)
+	if err != nil {
+		return nil, err
+	}
+	return &common.AuthenticationResult{Authenticated: false, FailedAuthAttempts: dat.FailedAuthAttempts, LockedUntil: dat.LockedUntil}, nil
+}
 
+// findEvaluatorTech index of the tech in the tech repus of the evaluator, -1 if the evaluator does not have it
+func findEvaluatorTech(dat *Evaluator, techName string) int {
 	for i, techRepuData := range dat.EvaluatorTechRepus {
 		if techRepuData.UniqueTechName == techName {
-			dat.EvaluatorTechRepus[i].AttainedRepu += upCount
-			flag = true
-			break
+			return i
 		}
 	}
+	return -1
+}
 
-	if !flag {
-		return shim.Error("tech repu not found for evaluator " + evaluatorID)
+// adjustRepu changes the evaluator's reputation in the tech by delta without dropping below 0, nil when the evaluator
+// does not hold the tech, it leaves writing the evaluator and raising the returned event to the caller
+func adjustRepu(dat *Evaluator, techName string, delta int, changedOn string) *ReputationChangedEvent {
+	index := findEvaluatorTech(dat, techName)
+	if index < 0 {
+		return nil
 	}
+	repuChange := &ReputationChangedEvent{SubjectType: "EVALUATOR", SubjectID: dat.EvaluatorID, TechName: techName, ChangedOn: changedOn}
+	repuChange.PreviousRepu = dat.EvaluatorTechRepus[index].AttainedRepu
+	dat.EvaluatorTechRepus[index].AttainedRepu += delta
+	if dat.EvaluatorTechRepus[index].AttainedRepu < 0 {
+		dat.EvaluatorTechRepus[index].AttainedRepu = 0
+	}
+	repuChange.AttainedRepu = dat.EvaluatorTechRepus[index].AttainedRepu
+	return repuChange
+}
 
-	updatedEvaluator := Evaluator{dat.EvaluatorID, dat.EvaluatorSecret, dat.EvaluatedAnswers, dat.EvaluatorTechRepus, dat.CreatedON}
-
-	buff, err := EvaltoJSON(updatedEvaluator)
+// findEvaluatorTechByName the canonical id of the tech name and its index in the tech repus of the evaluator, -1 if the evaluator does
+// not have it, a legacy tech stored under the exact name is found without asking the tech registry
+func findEvaluatorTechByName(ctx TransactionContextInterface, techsChaincode string, dat *Evaluator, techName string) (string, int, error) {
+	index := findEvaluatorTech(dat, techName)
+	if index >= 0 {
+		return techName, index, nil
+	}
+	tech, err := common.ResolveTech(ctx, techsChaincode, techName)
 	if err != nil {
-		errorStr := "updateDispatchOrder() : Failed Cannot create object buffer for write : " + args[1]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return "", -1, err
 	}
+	return tech.TechID, findEvaluatorTech(dat, tech.TechID), nil
+}
 
-	err = stub.PutState(dat.EvaluatorID, buff) //store marble with id as key
+// changeEvaluatorSecret stores the new secret and records why and when it changed, every change is kept in the key history
+func changeEvaluatorSecret(ctx TransactionContextInterface, dat *Evaluator, newSecret string, reason string) error {
+	rotatedOn, err := ctx.GetTxTime()
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
 	}
+	dat.EvaluatorSecret = common.HashSecret(ctx, newSecret)
+	dat.SecretRotatedOn = rotatedOn
+	dat.SecretRotationReason = reason
+	err = ctx.PutEvaluator(dat)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventSecretRotated, SecretRotatedEvent{"EVALUATOR", dat.EvaluatorID, reason, rotatedOn})
+	return nil
+}
 
-	fmt.Println("- end addAnEvaluator")
-	return shim.Success(nil)
+// redact the evaluator as every read hands it out, never with the hash of its secret or of its pending reset token
+func redact(evaluator *Evaluator) *Evaluator {
+	evaluator.EvaluatorSecret = ""
+	if evaluator.SecretReset != nil {
+		secretReset := *evaluator.SecretReset
+		secretReset.TokenHash = ""
+		evaluator.SecretReset = &secretReset
+	}
+	return evaluator
 }
 
-func updateTheEvaluatedAnswers(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
-	fmt.Println("starting updateTheEvaluatedAnswers")
+// ============================================================================================================================
+// Get history of asset
+// ============================================================================================================================
+func getHistory(ctx TransactionContextInterface, evaluatorId string) ([]AuditHistory, error) {
+	history := []AuditHistory{}
 
-	if len(args) != 2 {
-		return shim.Error("Incorrect number of arguments. Expecting 2")
-	}
+	fmt.Printf("- start getHistoryForEvaluator: %s\n", evaluatorId)
 
-	//input sanitation
-	err = sanitize_arguments(args)
+	// Get History
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(evaluatorId)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, common.InternalError(err, "unable to read the history of %s", evaluatorId)
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		historyData, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the history of %s", evaluatorId)
+		}
+
+		var tx AuditHistory
+		tx.TxId = historyData.TxId //copy transaction id over
+		if historyData.Value != nil {
+			var evaluator Evaluator
+			json.Unmarshal(historyData.Value, &evaluator) //un stringify it aka JSON.parse()
+			tx.Value = *redact(&evaluator)                //copy evaluator over
+		}
+		history = append(history, tx) //add this tx to the list
 	}
+	fmt.Printf("- getHistoryForEvaluator returning %d entries\n", len(history))
 
-	evaluatorID := args[0]
-	answerHashID := args[1]
+	return history, nil
+}
 
-	evaluatorAsBytes, err := stub.GetState(evaluatorID)
-	str := fmt.Sprintf("%s", evaluatorAsBytes)
-	fmt.Println("string is " + str)
+// CreateEvaluatorObject creates an evaluator asset with the hash of its secret, see common.HashSecret
+func CreateEvaluatorObject(evaluatorID string, hashedSecret string, techRepu TechRepu, createdOn string, enrollmentID string) Evaluator {
+	dummyTechRepuArray := []TechRepu{}
+	dummyTechRepuArray = append(dummyTechRepuArray, techRepu)
 
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Evaluator - " + evaluatorID)
-		return shim.Error("error in finding evaluator for - " + evaluatorID)
+	strArr := []string{}
+	myEvaluator := Evaluator{
+		EvaluatorID:        evaluatorID,
+		EvaluatorSecret:    hashedSecret,
+		EvaluatedAnswers:   strArr,
+		EvaluatorTechRepus: dummyTechRepuArray,
+		CreatedON:          createdOn,
+		EnrollmentID:       enrollmentID,
 	}
+	return myEvaluator
+}
+
+// CreateEvaluatorTechRepuObject creates a tech reputation entry
+func CreateEvaluatorTechRepuObject(techName string, createdOn string) TechRepu {
+	return TechRepu{UniqueTechName: techName, AttainedRepu: 10, CreatedON: createdOn}
+}
 
-	dat, err := JSONtoEval(evaluatorAsBytes)
+func EvaltoJSON(eval Evaluator) ([]byte, error) {
+
+	djson, err := json.Marshal(eval)
 	if err != nil {
-		return shim.Error("unable to convert jsonToDoc for" + evaluatorID)
-	}
-	evalAnswers := dat.EvaluatedAnswers
-	if contains(evalAnswers, answerHashID) {
-		errorStr := "already evaluated cant evaluate the same answer again "
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		fmt.Println(err)
+		return nil, err
 	}
-	evalAnswers = append(evalAnswers, answerHashID)
+	return djson, nil
+}
 
-	updatedEvaluator := Evaluator{dat.EvaluatorID, dat.EvaluatorSecret, evalAnswers, dat.EvaluatorTechRepus, dat.CreatedON}
+func JSONtoEval(data []byte) (Evaluator, error) {
 
-	buff, err := EvaltoJSON(updatedEvaluator)
+	eval := Evaluator{}
+	err := json.Unmarshal([]byte(data), &eval)
 	if err != nil {
-		errorStr := "updateDispatchOrder() : Failed Cannot create object buffer for write : " + args[1]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		fmt.Println("Unmarshal failed : ", err)
+		return eval, err
 	}
 
-	err = stub.PutState(dat.EvaluatorID, buff) //store marble with id as key
+	return eval, nil
+}
+
+func getQueryResultForQueryString(ctx TransactionContextInterface, queryString string) ([]*EvaluatorQueryResult, error) {
+
+	fmt.Printf("- getQueryResultForQueryString queryString:\n%s\n", queryString)
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, common.InternalError(err, "rich query failed")
 	}
+	defer resultsIterator.Close()
 
-	fmt.Println("- end updateTheEvaluatedAnswers")
-	return shim.Success(nil)
+	results := []*EvaluatorQueryResult{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the query result")
+		}
+
+		eval, err := JSONtoEval(queryResponse.Value)
+		if err != nil {
+			return nil, common.InternalError(err, "unable to unmarshall evaluator - %s", queryResponse.Key)
+		}
+		results = append(results, &EvaluatorQueryResult{Key: queryResponse.Key, Record: redact(&eval)})
+	}
+
+	fmt.Printf("- getQueryResultForQueryString found %d records\n", len(results))
+
+	return results, nil
 }
 
 func contains(techRepuArray []string, match string) bool {
@@ -617,13 +1316,3 @@ func stringInSlice(a string, list []string) bool {
 	}
 	return false
 }
-
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
-}
-
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/Common"
+)
+
+// ============================================================================================================================
+// Configuration - one record per chaincode, changed by an admin through SetConfig
+// ============================================================================================================================
+
+// Config the settings of the chaincode, the defaults apply until an admin sets it
+type Config struct {
+	// the lowest MinEvaluatorRepu a question can demand, also the minimum of a question submitted without one
+	MinEvaluatorRepuFloor int `json:"MinEvaluatorRepuFloor" validate:"minimum=1,maximum=1000000"`
+}
+
+var defaultConfig = Config{
+	MinEvaluatorRepuFloor: common.DefaultMinEvaluatorRepu,
+}
+
+// GetConfig reads the config from the world state, the defaults if no config was set
+func (ctx *TransactionContext) GetConfig() (*Config, error) {
+	config := defaultConfig
+	err := common.ReadConfig(ctx, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
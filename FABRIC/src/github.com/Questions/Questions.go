@@ -1,19 +1,16 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strconv"
-	"time"
 
-	"github.com/hyperledger/fabric/core/chaincode/shim"
-	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/Common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
-// QuestionChaincode example simple Chaincode implementation
+// QuestionChaincode contract for storing and querying questions
 type QuestionChaincode struct {
+	contractapi.Contract
 }
 
 // ============================================================================================================================
@@ -26,270 +23,354 @@ type Question struct {
 	QuestionerID              string `json:"QuestionerID"`
 	QuestionTech              string `json:"QuestionTech"`
 	RequiredEvaluatorThumbsUp int    `json:"RequiredEvaluatorThumbsUp"`
-	QuestionedOn              string `json:"QuestionedOn'`
+	QuestionedOn              string `json:"QuestionedOn"`
+	// the reputation an evaluator needs in the tech to give a thumbs up, questions submitted before it was introduced
+	// have none and keep requiring more than 1000
+	MinEvaluatorRepu int `json:"MinEvaluatorRepu,omitempty"`
+	// prerequisites a student has to meet to answer the question, a minimum reputation in a tech and the questions
+	// the student must already have an accepted answer to
+	PrerequisiteTech      string   `json:"PrerequisiteTech,omitempty"`
+	PrerequisiteRepu      int      `json:"PrerequisiteRepu,omitempty"`
+	PrerequisiteQuestions []string `json:"PrerequisiteQuestions,omitempty"`
+	// the id of the enrollment certificate that submitted the question, its accounts cannot evaluate the answers
+	QuestionerEnrollmentID string `json:"QuestionerEnrollmentID,omitempty"`
+	// the distinct organizations whose evaluators have to give a thumbs up before an answer is accepted,
+	// questions submitted before it was introduced have none and only count thumbs up
+	RequiredOrganizations int `json:"RequiredOrganizations,omitempty"`
+	// the time answers close, a question with a close time takes committed answers until then which are revealed
+	// after it, questions without one take answers in the open at any time
+	ClosesOn string `json:"ClosesOn,omitempty"`
+}
+
+// MaxPrerequisiteQuestions the most questions a question can require accepted answers to
+const MaxPrerequisiteQuestions = 20
+
+// QuestionQueryResult structure used for handling result of rich queries
+type QuestionQueryResult struct {
+	Key    string    `json:"Key"`
+	Record *Question `json:"Record"`
 }
 
 // ============================================================================================================================
-// Main
+// Event Definitions - the events raised by the question functions, see Common/Events.go
 // ============================================================================================================================
-func main() {
-	err := shim.Start(new(QuestionChaincode))
-	if err != nil {
-		fmt.Printf("Error starting Simple chaincode - %s", err)
-	}
-}
+
+// EventQuestionSubmitted is raised by SubmitQuestion, its payload is the submitted Question
+const EventQuestionSubmitted = "QuestionSubmitted"
 
 // ============================================================================================================================
-// Init - initialize the chaincode
+// Request Objects - the JSON request accepted by each function and its declared schema
 // ============================================================================================================================
-func (t *QuestionChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
-	fmt.Println("Question Store Channel Is Starting Up")
-	funcName, args := stub.GetFunctionAndParameters()
-	var err error
-	txId := stub.GetTxID()
 
-	fmt.Println("  Init() is running")
-	fmt.Println("  Transaction ID: ", txId)
-	fmt.Println("  GetFunctionAndParameters() function: ", funcName)
-	fmt.Println("  GetFunctionAndParameters() args count: ", len(args))
-	fmt.Println("  GetFunctionAndParameters() args found: ", args)
+// InitLedgerRequest request object of InitLedger
+type InitLedgerRequest struct {
+	Key   string `json:"Key" validate:"maxlength=64"`
+	Value string `json:"Value" validate:"maxlength=256"`
+}
 
-	// expecting 1 arg for instantiate or upgrade
-	if len(args) == 2 {
-		fmt.Println("  GetFunctionAndParameters() : Number of arguments", len(args))
-	}
-	// this is a very simple test. let's write to the ledger and error out on any errors
-	// it's handy to read this right away to verify network is healthy if it wrote the correct value
-	err = stub.PutState(args[0], []byte(args[1]))
-	if err != nil {
-		return shim.Error(err.Error()) //self-test fail
-	}
+// SubmitQuestionRequest request object of SubmitQuestion
+type SubmitQuestionRequest struct {
+	QuestionHashID            string   `json:"QuestionHashID" validate:"format=hash"`
+	QuestionCID               string   `json:"QuestionCID" validate:"maxlength=128,format=cid"`
+	QuestionerID              string   `json:"QuestionerID" validate:"maxlength=64,format=id"`
+	QuestionTech              string   `json:"QuestionTech" validate:"maxlength=64,format=tech"`
+	RequiredEvaluatorThumbsUp int      `json:"RequiredEvaluatorThumbsUp" validate:"minimum=1,maximum=100"`
+	MinEvaluatorRepu          int      `json:"MinEvaluatorRepu" metadata:",optional" validate:"minimum=1,maximum=1000000"`
+	PrerequisiteTech          string   `json:"PrerequisiteTech" metadata:",optional" validate:"maxlength=64,format=tech"`
+	PrerequisiteRepu          int      `json:"PrerequisiteRepu" metadata:",optional" validate:"minimum=1,maximum=1000000"`
+	PrerequisiteQuestions     []string `json:"PrerequisiteQuestions" metadata:",optional" validate:"format=hash"`
+	RequiredOrganizations     int      `json:"RequiredOrganizations" metadata:",optional" validate:"minimum=1,maximum=20"`
+	AnswerWindowMinutes       int      `json:"AnswerWindowMinutes" metadata:",optional" validate:"minimum=1,maximum=525600"`
+	TechsChaincode            string   `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+}
 
-	fmt.Println("Ready for action") //self-test pass
-	return shim.Success(nil)
+// QuestionIDRequest request object of the functions reading a single question
+type QuestionIDRequest struct {
+	QuestionHashID string `json:"QuestionHashID" validate:"format=hash"`
 }
 
 // ============================================================================================================================
-// Invoke - Our entry point for Invocations
+// Transaction Context - helpers available to every question transaction
 // ============================================================================================================================
-func (t *QuestionChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
-	function, args := stub.GetFunctionAndParameters()
-	fmt.Println(" ")
-	fmt.Println("starting invoke, for - " + function)
-
-	// Handle different functions
-	if function == "submitQuestion" { //create a new marble
-		return submitQuestion(stub, args)
-	} else if function == "queryQuestionById" {
-		return queryQuestionById(stub, args)
-	} else if function == "getQuestionById" {
-		return getQuestionById(stub, args)
-	}
-
-	// error out
-	fmt.Println("Received unknown invoke function name - " + function)
-	return shim.Error("Received unknown invoke function name - '" + function + "'")
+
+// TransactionContextInterface is the context handed to every QuestionChaincode function
+type TransactionContextInterface interface {
+	common.TransactionContextInterface
+	GetQuestion(questionHashID string) (*Question, error)
+	PutQuestion(question *Question) error
+	GetConfig() (*Config, error)
 }
 
-// ============================================================================================================================
-// Query - legacy function
-// ============================================================================================================================
-func (t *QuestionChaincode) Query(stub shim.ChaincodeStubInterface) pb.Response {
-	return shim.Error("Unknown supported call - Query()")
+// TransactionContext implementation of TransactionContextInterface
+type TransactionContext struct {
+	common.TransactionContext
+}
+
+// GetQuestion reads a question from the world state, nil if it does not exist
+func (ctx *TransactionContext) GetQuestion(questionHashID string) (*Question, error) {
+	questionAsBytes, err := ctx.GetStub().GetState(questionHashID)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding question for - %s", questionHashID)
+	}
+	if questionAsBytes == nil {
+		return nil, nil
+	}
+
+	ques, err := JSONtoQues(questionAsBytes)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall question - %s", questionHashID)
+	}
+	return &ques, nil
+}
+
+// PutQuestion writes a question to the world state keyed by its hash id
+func (ctx *TransactionContext) PutQuestion(question *Question) error {
+	buff, err := QuestoJSON(*question)
+	if err != nil {
+		return common.InternalError(err, "unable to convert question to json")
+	}
+	err = ctx.GetStub().PutState(question.QuestionHashID, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write question - %s", question.QuestionHashID)
+	}
+	return nil
 }
 
 // ============================================================================================================================
-// Get Question - get a question asset from ledger
+// Main
 // ============================================================================================================================
-func get_question(stub shim.ChaincodeStubInterface, id string) (Question, error) {
-	ques := Question{}
-	questionAsBytes, err := stub.GetState(id) //getState retreives a key/value from the ledger
-	if err == nil {                           //this seems to always succeed, even if key didn't exist
-		return ques, errors.New("Failed to find marble - " + id)
+func main() {
+	chaincode, err := newQuestionChaincode()
+	if err != nil {
+		fmt.Printf("Error creating Question chaincode - %s", err)
+		return
 	}
 
-	/*fmt.Println("question id from question is " + question.QuestionID)
-	fmt.Println("question id of requested question is " + id)*/
+	err = chaincode.Start()
+	if err != nil {
+		fmt.Printf("Error starting Question chaincode - %s", err)
+	}
+}
 
-	if questionAsBytes == nil { //test if marble is actually here or just nil
-		return ques, errors.New("Question does not exist - " + id)
+// newQuestionChaincode the contract api chaincode of the Question contract, fails when the contract's metadata is rejected
+func newQuestionChaincode() (*contractapi.ContractChaincode, error) {
+	questionChaincode := new(QuestionChaincode)
+	questionChaincode.TransactionContextHandler = new(TransactionContext)
+	questionChaincode.UnknownTransaction = common.UnknownTransaction
+	questionChaincode.BeforeTransaction = common.BeforeTransaction(questionChaincode)
+	questionChaincode.AfterTransaction = common.AfterTransaction
+
+	chaincode, err := contractapi.NewChaincode(questionChaincode)
+	if err != nil {
+		return nil, err
 	}
+	chaincode.Info.Title = "Questions"
+	chaincode.Info.Version = "2.0.0"
+	return chaincode, nil
+}
 
-	err = json.Unmarshal([]byte(questionAsBytes), &ques)
+// ============================================================================================================================
+// InitLedger - initialize the chaincode
+// ============================================================================================================================
+func (t *QuestionChaincode) InitLedger(ctx TransactionContextInterface, req InitLedgerRequest) error {
+	fmt.Println("Question Store Channel Is Starting Up")
+	fmt.Println("  InitLedger() is running")
+	fmt.Println("  Transaction ID: ", ctx.GetStub().GetTxID())
+
+	// this is a very simple test. let's write to the ledger and error out on any errors
+	// it's handy to read this right away to verify network is healthy if it wrote the correct value
+	err := ctx.GetStub().PutState(req.Key, []byte(req.Value))
 	if err != nil {
-		fmt.Println("Unmarshal failed : ", err)
-		return ques, errors.New("unable to unmarshall")
+		return common.InternalError(err, "self-test write failed") //self-test fail
 	}
 
-	fmt.Println(ques)
-	return ques, nil
+	fmt.Println("Ready for action") //self-test pass
+	return nil
 }
 
-func submitQuestion(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
+// SubmitQuestion stores a new question against its hash id
+func (t *QuestionChaincode) SubmitQuestion(ctx TransactionContextInterface, req SubmitQuestionRequest) error {
 	fmt.Println("starting submitQuestion")
 
-	if len(args) != 5 {
-		fmt.Println("initQuestion(): Incorrect number of arguments. Expecting 5 ")
-		return shim.Error("intQuestion(): Incorrect number of arguments. Expecting 5 ")
+	fieldErrors := common.CheckCIDBinding("QuestionCID", req.QuestionCID, "QuestionHashID", req.QuestionHashID)
+	if (req.PrerequisiteTech == "") != (req.PrerequisiteRepu == 0) {
+		fieldErrors = append(fieldErrors, common.FieldError{Field: "PrerequisiteRepu", Message: "must be given together with PrerequisiteTech"})
+	}
+	if req.RequiredOrganizations > req.RequiredEvaluatorThumbsUp {
+		fieldErrors = append(fieldErrors, common.FieldError{Field: "RequiredOrganizations", Message: "must not be more than RequiredEvaluatorThumbsUp"})
+	}
+	if len(req.PrerequisiteQuestions) > MaxPrerequisiteQuestions {
+		fieldErrors = append(fieldErrors, common.FieldError{Field: "PrerequisiteQuestions", Message: fmt.Sprintf("must have at most %d questions", MaxPrerequisiteQuestions)})
+	}
+	if len(fieldErrors) > 0 {
+		return common.ValidationError("SubmitQuestion", fieldErrors)
 	}
 
-	//input sanitation
-	err1 := sanitize_arguments(args)
-	if err1 != nil {
-		return shim.Error("Cannot sanitize arguments")
+	//check if question id already exists
+	existingQuestion, err := ctx.GetQuestion(req.QuestionHashID)
+	if err != nil {
+		return err
+	}
+	if existingQuestion != nil {
+		return common.NewError(common.ErrAlreadyExists, "This question already exists - %s", req.QuestionHashID)
 	}
 
-	questionHashID := args[0]
+	// the question is stored against the canonical tech id so it matches the evaluators' techs
+	tech, err := common.ResolveTech(ctx, req.TechsChaincode, req.QuestionTech)
+	if err != nil {
+		return err
+	}
+	req.QuestionTech = tech.TechID
 
-	//check if marble id already exists
-	questionAsBytes, err := stub.GetState(questionHashID)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		return shim.Error("error in finding question for - " + questionHashID)
+	config, err := ctx.GetConfig()
+	if err != nil {
+		return err
+	}
+	if req.RequiredOrganizations == 0 {
+		req.RequiredOrganizations = 1
+	}
+	if req.MinEvaluatorRepu == 0 {
+		req.MinEvaluatorRepu = config.MinEvaluatorRepuFloor
 	}
-	if questionAsBytes != nil {
-		fmt.Println("This question already exists - " + questionHashID)
-		return shim.Error("This question already exists - " + questionHashID) //all stop a marble by this id exists
+	if req.MinEvaluatorRepu < config.MinEvaluatorRepuFloor {
+		return common.ValidationError("SubmitQuestion", []common.FieldError{{Field: "MinEvaluatorRepu", Message: fmt.Sprintf("must be at least the floor of %d", config.MinEvaluatorRepuFloor)}})
 	}
 
-	questionObject, err := CreateQuestionObject(args[0:])
-	if err != nil {
-		errorStr := "initQuestion() : Failed Cannot create object buffer for write : " + args[0]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+	if req.PrerequisiteTech != "" {
+		prerequisiteTech, err := common.ResolveTech(ctx, req.TechsChaincode, req.PrerequisiteTech)
+		if err != nil {
+			return err
+		}
+		req.PrerequisiteTech = prerequisiteTech.TechID
+	}
+	for i, prerequisiteID := range req.PrerequisiteQuestions {
+		prerequisite, err := ctx.GetQuestion(prerequisiteID)
+		if err != nil {
+			return err
+		}
+		if prerequisite == nil || prerequisiteID == req.QuestionHashID {
+			fieldErrors = append(fieldErrors, common.FieldError{Field: fmt.Sprintf("PrerequisiteQuestions[%d]", i), Message: "is not a submitted question"})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return common.ValidationError("SubmitQuestion", fieldErrors)
 	}
 
-	fmt.Println(questionObject)
-	buff, err := QuestoJSON(questionObject)
+	questionedOn, err := ctx.GetTxTime()
 	if err != nil {
-		return shim.Error("unable to convert question to json")
+		return err
 	}
 
-	err = stub.PutState(questionHashID, buff) //store marble with id as key
+	enrollmentID, err := common.GetClientID(ctx)
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
 	}
 
-	fmt.Println("- end submitQuestion")
-	return shim.Success(nil)
-}
+	closesOn := ""
+	if req.AnswerWindowMinutes > 0 {
+		closesOn, err = common.AddMinutes(questionedOn, req.AnswerWindowMinutes)
+		if err != nil {
+			return err
+		}
+	}
 
-// query callback representing the query of a chaincode
-func getQuestionById(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
+	questionObject := CreateQuestionObject(req, questionedOn, enrollmentID, closesOn)
+	fmt.Println(questionObject)
 
-	if len(args) != 1 {
-		return shim.Error("Incorrect number of arguments. Expecting name of the person to query")
+	err = ctx.PutQuestion(&questionObject)
+	if err != nil {
+		return err
 	}
+	ctx.EmitEvent(EventQuestionSubmitted, questionObject)
 
-	questionID := args[0]
+	fmt.Println("- end submitQuestion")
+	return nil
+}
 
-	// Get the state from the ledger
-	questionbytes, err := stub.GetState(questionID)
+// GetQuestionById returns the question stored against the hash id, required by the Answer chaincode
+func (t *QuestionChaincode) GetQuestionById(ctx TransactionContextInterface, req QuestionIDRequest) (*Question, error) {
+	question, err := ctx.GetQuestion(req.QuestionHashID)
 	if err != nil {
-		jsonResp := "{\"Error\":\"Failed to get state for " + questionID + "\"}"
-		return shim.Error(jsonResp)
+		return nil, err
 	}
-
-	if questionbytes == nil {
-		jsonResp := "{\"Error\":\"Nil data for " + questionID + "\"}"
-		return shim.Error(jsonResp)
+	if question == nil {
+		return nil, common.NewError(common.ErrNotFound, "Nil data for %s", req.QuestionHashID)
 	}
 
-	jsonResp := "{\"QuestionID\":\"" + questionID + "\",\"data\":\"" + string(questionbytes) + "\"}"
-	fmt.Printf("Query Response:%s\n", jsonResp)
-	return shim.Success(questionbytes)
+	fmt.Printf("Query Response:%v\n", question)
+	return question, nil
 }
 
-func queryQuestionById(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-
-	//   0
-	// "bob"
-	if len(args) != 1 {
-		return shim.Error("Incorrect number of arguments. Expecting 1")
-	}
+// QueryQuestionById rich query for the question by its hash id
+func (t *QuestionChaincode) QueryQuestionById(ctx TransactionContextInterface, req QuestionIDRequest) ([]*QuestionQueryResult, error) {
+	queryString := fmt.Sprintf("{\"selector\":{\"QuestionHashID\":\"%s\"}}", req.QuestionHashID)
 
-	questionHashID := args[0]
-	queryString := fmt.Sprintf("{\"selector\":{\"QuestionHashID\":\"%s\"}}", questionHashID)
+	return getQueryResultForQueryString(ctx, queryString)
+}
 
-	queryResults, err := getQueryResultForQueryString(stub, queryString)
-	if err != nil {
-		return shim.Error(err.Error())
-	}
-	return shim.Success(queryResults)
+// SetConfig admin only, replaces the evaluator reputation floor
+func (t *QuestionChaincode) SetConfig(ctx TransactionContextInterface, config Config) error {
+	return common.SetConfig(ctx, &config, defaultConfig)
 }
 
-// =========================================== Private Libraries ========================================================
+// GetConfig returns the settings in use, the defaults until an admin set them
+func (t *QuestionChaincode) GetConfig(ctx TransactionContextInterface) (*Config, error) {
+	return ctx.GetConfig()
+}
 
-// ========================================================
-// Input Sanitation - dumb input checking, look for empty strings
-// ========================================================
-func sanitize_arguments(strs []string) error {
-	for i, val := range strs {
-		if len(val) <= 0 {
-			return errors.New("Argument " + strconv.Itoa(i) + " must be a non-empty string")
-		}
-		// if len(val) > 32 {
-		// 	return errors.New("Argument " + strconv.Itoa(i) + " must be <= 32 characters")
-		// }
-	}
-	return nil
+// GetRequestSchemas lists the declared request schema of every function
+func (t *QuestionChaincode) GetRequestSchemas(ctx TransactionContextInterface) []common.RequestSchema {
+	return common.RequestSchemas(t)
 }
 
-func getQueryResultForQueryString(stub shim.ChaincodeStubInterface, queryString string) ([]byte, error) {
+// =========================================== Private Libraries ========================================================
+
+func getQueryResultForQueryString(ctx TransactionContextInterface, queryString string) ([]*QuestionQueryResult, error) {
 
 	fmt.Printf("- getQueryResultForQueryString queryString:\n%s\n", queryString)
 
-	resultsIterator, err := stub.GetQueryResult(queryString)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
 	if err != nil {
-		return nil, err
+		return nil, common.InternalError(err, "rich query failed")
 	}
 	defer resultsIterator.Close()
 
-	// buffer is a JSON array containing QueryRecords
-	var buffer bytes.Buffer
-	buffer.WriteString("[")
-
-	bArrayMemberAlreadyWritten := false
+	results := []*QuestionQueryResult{}
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
 		if err != nil {
-			return nil, err
-		}
-		// Add a comma before array members, suppress it for the first array member
-		if bArrayMemberAlreadyWritten == true {
-			buffer.WriteString(",")
+			return nil, common.InternalError(err, "unable to iterate the query result")
 		}
-		buffer.WriteString("{\"Key\":")
-		buffer.WriteString("\"")
-		buffer.WriteString(queryResponse.Key)
-		buffer.WriteString("\"")
 
-		buffer.WriteString(", \"Record\":")
-		// Record is a JSON object, so we write as-is
-		buffer.WriteString(string(queryResponse.Value))
-		buffer.WriteString("}")
-		bArrayMemberAlreadyWritten = true
+		ques, err := JSONtoQues(queryResponse.Value)
+		if err != nil {
+			return nil, common.InternalError(err, "unable to unmarshall question - %s", queryResponse.Key)
+		}
+		results = append(results, &QuestionQueryResult{Key: queryResponse.Key, Record: &ques})
 	}
-	buffer.WriteString("]")
 
-	fmt.Printf("- getQueryResultForQueryString queryResult:\n%s\n", buffer.String())
+	fmt.Printf("- getQueryResultForQueryString found %d records\n", len(results))
 
-	return buffer.Bytes(), nil
+	return results, nil
 }
 
-// CreateAssetObject creates an asset
-func CreateQuestionObject(args []string) (Question, error) {
-	var myQuestion Question
-
-	// Check there are 10 Arguments provided as per the the struct
-	if len(args) != 5 {
-		fmt.Println("CreateQuestionObject(): Incorrect number of arguments. Expecting 5 ")
-		return myQuestion, errors.New("CreateQuestionObject(): Incorrect number of arguments. Expecting 5 ")
+// CreateQuestionObject creates a question asset
+func CreateQuestionObject(req SubmitQuestionRequest, questionedOn string, enrollmentID string, closesOn string) Question {
+	return Question{
+		QuestionHashID:            req.QuestionHashID,
+		QuestionCID:               req.QuestionCID,
+		QuestionerID:              req.QuestionerID,
+		QuestionTech:              req.QuestionTech,
+		RequiredEvaluatorThumbsUp: req.RequiredEvaluatorThumbsUp,
+		QuestionedOn:              questionedOn,
+		MinEvaluatorRepu:          req.MinEvaluatorRepu,
+		PrerequisiteTech:          req.PrerequisiteTech,
+		PrerequisiteRepu:          req.PrerequisiteRepu,
+		PrerequisiteQuestions:     req.PrerequisiteQuestions,
+		QuestionerEnrollmentID:    enrollmentID,
+		RequiredOrganizations:     req.RequiredOrganizations,
+		ClosesOn:                  closesOn,
 	}
-	requiredEvaluatorThumbsUp, _ := strconv.Atoi(args[4])
-	myQuestion = Question{args[0], args[1], args[2], args[3], requiredEvaluatorThumbsUp, time.Now().Format("20060102150405")}
-	return myQuestion, nil
 }
 
 func QuestoJSON(ques Question) ([]byte, error) {
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Common"
+)
+
+func TestNewQuestionChaincode(t *testing.T) {
+	_, err := newQuestionChaincode()
+	if err != nil {
+		t.Errorf("newQuestionChaincode failed - %s", err)
+	}
+	// panics on a constraint that is not a number
+	common.RequestSchemas(new(QuestionChaincode))
+}
@@ -1,20 +1,20 @@
 package main
 
 import (
-	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strconv"
-	"time"
+	"strings"
 
-	"github.com/hyperledger/fabric/core/chaincode/shim"
-	pb "github.com/hyperledger/fabric/protos/peer"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/Common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
-// StudentChaincode class
+// StudentChaincode contract for registering students and tracking their reputation
 type StudentChaincode struct {
+	contractapi.Contract
 }
 
 // ============================================================================================================================
@@ -22,390 +22,877 @@ type StudentChaincode struct {
 // ============================================================================================================================
 
 type Student struct {
-	StudentID         string     `json:"StudentID"`
-	StudentSecret     string     `json:"StudentSecret"`
-	StudentTechRepus  []TechRepu `json:"StudentTechRepos"`
-	AnsweredQuestions []string   `json:"AnsweredQuestions"`
-	CreatedON         string     `json:"createdOn"`
+	StudentID            string       `json:"StudentID"`
+	StudentSecret        string       `json:"StudentSecret"`
+	StudentTechRepus     []TechRepu   `json:"StudentTechRepos"`
+	AnsweredQuestions    []string     `json:"AnsweredQuestions"`
+	CreatedON            string       `json:"createdOn"`
+	SecretRotatedOn      string       `json:"SecretRotatedOn,omitempty"`
+	SecretRotationReason string       `json:"SecretRotationReason,omitempty"`
+	SecretReset          *SecretReset `json:"SecretReset,omitempty"`
+	// the id of the enrollment certificate that registered the student, accounts registered by the same enrollment
+	// are linked and never evaluate each other
+	EnrollmentID string `json:"EnrollmentID,omitempty"`
+	common.AuthState
 }
 
+// SecretReset a pending admin issued reset of the secret, only the sha256 of the one-time reset token is stored
+type SecretReset struct {
+	TokenHash string `json:"TokenHash"`
+	IssuedBy  string `json:"IssuedBy"`
+	IssuedOn  string `json:"IssuedOn"`
+	ExpiresOn string `json:"ExpiresOn"`
+}
+
+// reasons recorded on the student whenever the secret changes
+const (
+	SecretRotationReasonRotated = "ROTATED"
+	SecretRotationReasonReset   = "ADMIN_RESET"
+)
+
 type TechRepu struct {
 	UniqueTechName string `json:"UniqueTechName"`
 	AttainedRepu   int    `json:"AttainedRepo"`
 	CreatedON      string `json:"createdOn"`
 }
 
+// StudentQueryResult structure used for handling result of rich queries
+type StudentQueryResult struct {
+	Key    string   `json:"Key"`
+	Record *Student `json:"Record"`
+}
+
+// AuditHistory one entry of the key history of a student
+type AuditHistory struct {
+	TxId  string  `json:"txId"`
+	Value Student `json:"value"`
+}
+
 // ============================================================================================================================
-// Main
+// Event Definitions - the events raised by the student functions and their payloads, see Common/Events.go
 // ============================================================================================================================
-func main() {
-	err := shim.Start(new(StudentChaincode))
-	if err != nil {
-		fmt.Printf("Error starting Student chaincode - %s", err)
-	}
+
+// names of the events raised by the student functions
+const (
+	EventStudentRegistered = "StudentRegistered"
+	EventReputationChanged = "ReputationChanged"
+	EventSecretRotated     = "SecretRotated"
+	EventSecretResetIssued = "SecretResetIssued"
+	EventTechAdded         = "TechAdded"
+	EventTechRemoved       = "TechRemoved"
+)
+
+// StudentRegisteredEvent payload of StudentRegistered, raised by AddAStudent, never carries the secret
+type StudentRegisteredEvent struct {
+	StudentID       string `json:"StudentID"`
+	InitialTechName string `json:"InitialTechName"`
+	AttainedRepu    int    `json:"AttainedRepu"`
+	CreatedOn       string `json:"CreatedOn"`
 }
 
-func (t *StudentChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
-	fmt.Println("Student Chaincode Is Starting Up")
-	funcName, args := stub.GetFunctionAndParameters()
-	var err error
-	txId := stub.GetTxID()
+// ReputationChangedEvent payload of ReputationChanged, raised by BumpUpStudentRepu with SubjectType STUDENT
+type ReputationChangedEvent struct {
+	SubjectType  string `json:"SubjectType"`
+	SubjectID    string `json:"SubjectID"`
+	TechName     string `json:"TechName"`
+	PreviousRepu int    `json:"PreviousRepu"`
+	AttainedRepu int    `json:"AttainedRepu"`
+	ChangedOn    string `json:"ChangedOn"`
+}
 
-	fmt.Println("  Init() is running")
-	fmt.Println("  Transaction ID: ", txId)
-	fmt.Println("  GetFunctionAndParameters() function: ", funcName)
-	fmt.Println("  GetFunctionAndParameters() args count: ", len(args))
-	fmt.Println("  GetFunctionAndParameters() args found: ", args)
+// SecretRotatedEvent payload of SecretRotated, raised by RotateStudentSecret and ResetStudentSecret with SubjectType STUDENT
+type SecretRotatedEvent struct {
+	SubjectType string `json:"SubjectType"`
+	SubjectID   string `json:"SubjectID"`
+	Reason      string `json:"Reason"`
+	RotatedOn   string `json:"RotatedOn"`
+}
+
+// SecretResetIssuedEvent payload of SecretResetIssued, raised by IssueStudentSecretReset, never carries the token
+type SecretResetIssuedEvent struct {
+	SubjectType string `json:"SubjectType"`
+	SubjectID   string `json:"SubjectID"`
+	IssuedOn    string `json:"IssuedOn"`
+	ExpiresOn   string `json:"ExpiresOn"`
+}
+
+// TechAddedEvent payload of TechAdded, raised by AddStudentTech and by BumpUpStudentRepu when it creates the tech
+type TechAddedEvent struct {
+	SubjectType  string `json:"SubjectType"`
+	SubjectID    string `json:"SubjectID"`
+	TechName     string `json:"TechName"`
+	AttainedRepu int    `json:"AttainedRepu"`
+	AddedOn      string `json:"AddedOn"`
+}
 
-	// expecting 1 arg for instantiate or upgrade
-	if len(args) == 2 {
-		fmt.Println("  GetFunctionAndParameters() : Number of arguments", len(args))
+// TechRemovedEvent payload of TechRemoved, raised by RemoveTech with the reputation that was dropped
+type TechRemovedEvent struct {
+	SubjectType  string `json:"SubjectType"`
+	SubjectID    string `json:"SubjectID"`
+	TechName     string `json:"TechName"`
+	AttainedRepu int    `json:"AttainedRepu"`
+	RemovedOn    string `json:"RemovedOn"`
+}
+
+// ============================================================================================================================
+// Request Objects - the JSON request accepted by each function and its declared schema
+// ============================================================================================================================
+
+// InitLedgerRequest request object of InitLedger
+type InitLedgerRequest struct {
+	Key   string `json:"Key" validate:"maxlength=64"`
+	Value string `json:"Value" validate:"maxlength=256"`
+}
+
+// AddAStudentRequest request object of AddAStudent
+type AddAStudentRequest struct {
+	StudentID       string `json:"StudentID" validate:"maxlength=64,format=id"`
+	InitialTechName string `json:"InitialTechName" validate:"maxlength=64,format=tech"`
+	TechsChaincode  string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+}
+
+// StudentTechRequest request object of the functions working on one tech of a student
+type StudentTechRequest struct {
+	StudentID      string `json:"StudentID" validate:"maxlength=64,format=id"`
+	TechName       string `json:"TechName" validate:"maxlength=64,format=tech"`
+	TechsChaincode string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+}
+
+// StudentIDRequest request object of the functions reading a single student
+type StudentIDRequest struct {
+	StudentID string `json:"StudentID" validate:"maxlength=64,format=id"`
+}
+
+// UpdateAnsweredQuestionsRequest request object of UpdateAnsweredQuestions
+type UpdateAnsweredQuestionsRequest struct {
+	StudentID  string `json:"StudentID" validate:"maxlength=64,format=id"`
+	QuestionID string `json:"QuestionID" validate:"format=hash"`
+}
+
+// StudentTechAuthRequest request object of the functions the student manages its techs with, the secret travels
+// in the transient map
+type StudentTechAuthRequest struct {
+	StudentID      string `json:"StudentID" validate:"maxlength=64,format=id"`
+	TechName       string `json:"TechName" validate:"maxlength=64,format=tech"`
+	TechsChaincode string `json:"TechsChaincode" validate:"maxlength=64,format=chaincode"`
+}
+
+// IssueStudentSecretResetRequest request object of IssueStudentSecretReset, the admin hands the reset token to the student
+// out of band and only sends its sha256
+type IssueStudentSecretResetRequest struct {
+	StudentID        string `json:"StudentID" validate:"maxlength=64,format=id"`
+	ResetTokenHash   string `json:"ResetTokenHash" validate:"format=hash"`
+	ExpiresInMinutes int    `json:"ExpiresInMinutes" validate:"minimum=1,maximum=10080"`
+}
+
+// ============================================================================================================================
+// Transaction Context - helpers available to every student transaction
+// ============================================================================================================================
+
+// TransactionContextInterface is the context handed to every StudentChaincode function
+type TransactionContextInterface interface {
+	common.TransactionContextInterface
+	GetStudent(studentID string) (*Student, error)
+	PutStudent(student *Student) error
+}
+
+// TransactionContext implementation of TransactionContextInterface
+type TransactionContext struct {
+	common.TransactionContext
+}
+
+// GetStudent reads a student from the world state, nil if it does not exist
+func (ctx *TransactionContext) GetStudent(studentID string) (*Student, error) {
+	studentAsBytes, err := ctx.GetStub().GetState(studentID)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding student for - %s", studentID)
+	}
+	if studentAsBytes == nil {
+		return nil, nil
 	}
 
-	err = stub.PutState(args[0], []byte(args[1]))
+	stu, err := JSONtoStu(studentAsBytes)
 	if err != nil {
-		return shim.Error(err.Error()) //self-test fail
+		return nil, common.InternalError(err, "unable to convert jsonToDoc for %s", studentID)
 	}
+	return &stu, nil
+}
 
-	fmt.Println("Ready for action") //self-test pass
-	return shim.Success(nil)
+// PutStudent writes a student to the world state keyed by the student id
+func (ctx *TransactionContext) PutStudent(student *Student) error {
+	buff, err := StuToJSON(*student)
+	if err != nil {
+		return common.InternalError(err, "unable to convert student to json")
+	}
+	err = ctx.GetStub().PutState(student.StudentID, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write student - %s", student.StudentID)
+	}
+	return nil
 }
 
 // ============================================================================================================================
-// Invoke - Our entry point for Invocations
+// Main
 // ============================================================================================================================
-func (t *StudentChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
-	function, args := stub.GetFunctionAndParameters()
-	fmt.Println(" ")
-	fmt.Println("starting invoke, for - " + function)
+func main() {
+	chaincode, err := newStudentChaincode()
+	if err != nil {
+		fmt.Printf("Error creating Student chaincode - %s", err)
+		return
+	}
 
-	// Handle different functions
-	if function == "addAStudent" { //create a new marble
-		return addAStudent(stub, args)
-	} else if function == "bumpUpStudentRepu" { //create a new marble
-		return bumpUpStudentRepu(stub, args)
-	} else if function == "queryStudentById" {
-		return queryStudentById(stub, args)
-	} else if function == "updateAnsweredQuestions" {
-		return updateAnsweredQuestions(stub, args)
+	err = chaincode.Start()
+	if err != nil {
+		fmt.Printf("Error starting Student chaincode - %s", err)
 	}
+}
 
-	// error out
-	fmt.Println("Received unknown invoke function name - " + function)
-	return shim.Error("Received unknown invoke function name - '" + function + "'")
+// newStudentChaincode the contract api chaincode of the Student contract, fails when the contract's metadata is rejected
+func newStudentChaincode() (*contractapi.ContractChaincode, error) {
+	studentChaincode := new(StudentChaincode)
+	studentChaincode.TransactionContextHandler = new(TransactionContext)
+	studentChaincode.UnknownTransaction = common.UnknownTransaction
+	studentChaincode.BeforeTransaction = common.BeforeTransaction(studentChaincode)
+	studentChaincode.AfterTransaction = common.AfterTransaction
+
+	chaincode, err := contractapi.NewChaincode(studentChaincode)
+	if err != nil {
+		return nil, err
+	}
+	chaincode.Info.Title = "Students"
+	chaincode.Info.Version = "2.0.0"
+	return chaincode, nil
 }
 
 // ============================================================================================================================
-// Query - legacy function
+// InitLedger - initialize the chaincode
 // ============================================================================================================================
-func (t *StudentChaincode) Query(stub shim.ChaincodeStubInterface) pb.Response {
-	return shim.Error("Unknown supported call - Query()")
+func (t *StudentChaincode) InitLedger(ctx TransactionContextInterface, req InitLedgerRequest) error {
+	fmt.Println("Student Chaincode Is Starting Up")
+	fmt.Println("  InitLedger() is running")
+	fmt.Println("  Transaction ID: ", ctx.GetStub().GetTxID())
+
+	err := ctx.GetStub().PutState(req.Key, []byte(req.Value))
+	if err != nil {
+		return common.InternalError(err, "self-test write failed") //self-test fail
+	}
+
+	fmt.Println("Ready for action") //self-test pass
+	return nil
 }
 
-func addAStudent(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
+// AddAStudent registers a new student with an initial tech reputation
+func (t *StudentChaincode) AddAStudent(ctx TransactionContextInterface, req AddAStudentRequest) error {
 	fmt.Println("starting addAnStudent")
 
-	if len(args) != 3 {
-		fmt.Println("initStudent(): Incorrect number of arguments. Expecting 3 ")
-		return shim.Error("intStudent(): Incorrect number of arguments. Expecting 3 ")
-	}
-
-	//input sanitation
-	err1 := sanitize_arguments(args)
-	if err1 != nil {
-		return shim.Error("Cannot sanitize arguments")
+	studentID := req.StudentID
+	studentSecret, err := common.GetTransientSecret(ctx, "AddAStudent", "StudentSecret", 8, 72)
+	if err != nil {
+		return err
 	}
 
-	studentInitialTechName := args[0]
-	studentID := args[1]
-
-	//check if marble id already exists
-	studentAsBytes, err := stub.GetState(studentID)
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		return shim.Error("error in finding student for - " + studentID)
+	//check if student id already exists
+	existingStudent, err := ctx.GetStudent(studentID)
+	if err != nil {
+		return err
 	}
-	if studentAsBytes != nil {
-		fmt.Println("This student already exists - " + studentID)
-		return shim.Error("This student already exists - " + studentID) //all stop a marble by this id exists
+	if existingStudent != nil {
+		return common.NewError(common.ErrAlreadyExists, "This student already exists - %s", studentID)
 	}
-	studentTechRepuObject, err := CreateStudentTechRepuObject(studentInitialTechName)
 
-	studentObject, err := CreateStudentObject(args[1:], studentTechRepuObject)
+	createdOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	enrollmentID, err := common.GetClientID(ctx)
 	if err != nil {
-		errorStr := "initStudent() : Failed Cannot create object buffer for write : " + args[0]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return err
 	}
 
-	fmt.Println(studentObject)
-	buff, err := StuToJSON(studentObject)
+	tech, err := common.ResolveTech(ctx, req.TechsChaincode, req.InitialTechName)
 	if err != nil {
-		return shim.Error("unable to convert student to json")
+		return err
 	}
+	studentTechRepuObject := CreateStudentTechRepuObject(tech.TechID, createdOn)
 
-	err = stub.PutState(studentID, buff) //store marble with id as key
+	studentObject := CreateStudentObject(studentID, common.HashSecret(ctx, studentSecret), studentTechRepuObject, createdOn, enrollmentID)
+
+	err = ctx.PutStudent(&studentObject)
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
 	}
+	ctx.EmitEvent(EventStudentRegistered, StudentRegisteredEvent{studentID, studentTechRepuObject.UniqueTechName, studentTechRepuObject.AttainedRepu, createdOn})
 
 	fmt.Println("- end addAnStudent")
-	return shim.Success(nil)
+	return nil
 }
 
-func bumpUpStudentRepu(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
+// BumpUpStudentRepu admin only, adds reputation to an existing tech of the student
+func (t *StudentChaincode) BumpUpStudentRepu(ctx TransactionContextInterface, req StudentTechRequest) error {
 	fmt.Println("starting bumpUpStudentRepu")
 
-	if len(args) != 2 {
-		return shim.Error("Incorrect number of arguments. Expecting 2")
+	err := common.AssertAdmin(ctx, "BumpUpStudentRepu")
+	if err != nil {
+		return err
 	}
 
-	//input sanitation
-	err = sanitize_arguments(args)
+	studentID := req.StudentID
+
+	dat, err := ctx.GetStudent(studentID)
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding student for - %s", studentID)
 	}
 
-	studentID := args[0]
-	techName := args[1]
+	changedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
 
-	studentAsBytes, err := stub.GetState(studentID)
-	str := fmt.Sprintf("%s", studentAsBytes)
-	fmt.Println("string is " + str)
+	techName, index, err := findStudentTechByName(ctx, req.TechsChaincode, dat, req.TechName)
+	if err != nil {
+		return err
+	}
+	repuChange := ReputationChangedEvent{SubjectType: "STUDENT", SubjectID: studentID, TechName: techName, ChangedOn: changedOn}
 
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Student - " + studentID)
-		return shim.Error("error in finding student for - " + studentID)
+	if index < 0 {
+		config, err := common.GetAccountConfig(ctx)
+		if err != nil {
+			return err
+		}
+		if !config.AutoCreateTechOnBump {
+			return common.NewError(common.ErrNotFound, "tech repu not found for student %s", studentID)
+		}
+		// the tech is created with the reputation it just earned
+		dat.StudentTechRepus = append(dat.StudentTechRepus, TechRepu{UniqueTechName: techName, AttainedRepu: 0, CreatedON: changedOn})
+		index = len(dat.StudentTechRepus) - 1
+		ctx.EmitEvent(EventTechAdded, TechAddedEvent{"STUDENT", studentID, techName, 10, changedOn})
 	}
+	repuChange.PreviousRepu = dat.StudentTechRepus[index].AttainedRepu
+	dat.StudentTechRepus[index].AttainedRepu += 10
+	repuChange.AttainedRepu = dat.StudentTechRepus[index].AttainedRepu
 
-	dat, err := JSONtoStu(studentAsBytes)
+	err = ctx.PutStudent(dat)
 	if err != nil {
-		return shim.Error("unable to convert jsonToDoc for" + studentID)
+		return err
 	}
+	ctx.EmitEvent(EventReputationChanged, repuChange)
 
-	flag := false
+	fmt.Println("- end bumpUpStudentRepu")
+	return nil
+}
 
-	for i, techRepuData := range dat.StudentTechRepus {
-		if techRepuData.UniqueTechName == techName {
-			dat.StudentTechRepus[i].AttainedRepu += 10
-			flag = true
-			break
-		}
+// GetStudentById returns the student stored against the id without its secret
+func (t *StudentChaincode) GetStudentById(ctx TransactionContextInterface, req StudentIDRequest) (*Student, error) {
+	studentID := req.StudentID
+
+	student, err := ctx.GetStudent(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, common.NewError(common.ErrNotFound, "Nil data for %s", studentID)
 	}
 
-	if !flag {
-		return shim.Error("tech repu not found for student " + studentID)
+	return redact(student), nil
+}
+
+// QueryStudentById very important as it is required by the Answer chaincode to query
+func (t *StudentChaincode) QueryStudentById(ctx TransactionContextInterface, req StudentIDRequest) ([]*StudentQueryResult, error) {
+	queryString := fmt.Sprintf("{\"selector\":{\"StudentID\":\"%s\"}}", req.StudentID)
+
+	return getQueryResultForQueryString(ctx, queryString)
+}
+
+// UpdateAnsweredQuestions records that the student answered a question, a question can only be answered once, only
+// called by an admin or through a trusted chaincode, the Answers chaincode submitting the answer
+func (t *StudentChaincode) UpdateAnsweredQuestions(ctx TransactionContextInterface, req UpdateAnsweredQuestionsRequest) error {
+	fmt.Println("starting updateStudentAnsweredQuestions")
+
+	err := common.AssertTrustedCaller(ctx, "UpdateAnsweredQuestions")
+	if err != nil {
+		return err
 	}
 
-	updatedStudent := Student{dat.StudentID, dat.StudentSecret, dat.StudentTechRepus, dat.AnsweredQuestions, dat.CreatedON}
+	studentID := req.StudentID
+	answeredQuestionID := req.QuestionID
 
-	buff, err := StuToJSON(updatedStudent)
+	dat, err := ctx.GetStudent(studentID)
 	if err != nil {
-		errorStr := "updateDispatchOrder() : Failed Cannot create object buffer for write : " + args[1]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding student for - %s", studentID)
+	}
+
+	if contains(dat.AnsweredQuestions, answeredQuestionID) {
+		return common.NewError(common.ErrInvalidState, "already answered cant repeat - %s", answeredQuestionID)
 	}
+	dat.AnsweredQuestions = append(dat.AnsweredQuestions, answeredQuestionID)
 
-	err = stub.PutState(dat.StudentID, buff) //store marble with id as key
+	err = ctx.PutStudent(dat)
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
 	}
 
-	fmt.Println("- end addAnStudent")
-	return shim.Success(nil)
+	fmt.Println("- end updateStudentAnsweredQuestions")
+	return nil
 }
 
-// very important as it is required by the Answer chaincode to query
-func queryStudentById(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+// RotateStudentSecret replaces the secret of the student, the current secret has to be given
+func (t *StudentChaincode) RotateStudentSecret(ctx TransactionContextInterface, req StudentIDRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting rotateStudentSecret")
 
-	if len(args) < 1 {
-		return shim.Error("Incorrect number of arguments. Expecting 1")
+	newSecret, err := common.GetTransientSecret(ctx, "RotateStudentSecret", "NewStudentSecret", 8, 72)
+	if err != nil {
+		return nil, err
+	}
+	dat, result, err := getAuthenticatedStudent(ctx, "RotateStudentSecret", req.StudentID)
+	if err != nil || !result.Authenticated {
+		return result, err
 	}
 
-	studentID := args[0]
+	err = changeStudentSecret(ctx, dat, newSecret, SecretRotationReasonRotated)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("- end rotateStudentSecret")
+	return result, nil
+}
 
-	queryString := fmt.Sprintf("{\"selector\":{\"StudentID\":\"%s\"}}", studentID)
+// IssueStudentSecretReset admin only, lets the student set a new secret without the current one by presenting the reset token
+// before it expires, a new reset replaces a pending one
+func (t *StudentChaincode) IssueStudentSecretReset(ctx TransactionContextInterface, req IssueStudentSecretResetRequest) error {
+	fmt.Println("starting issueStudentSecretReset")
 
-	queryResults, err := getQueryResultForQueryString(stub, queryString)
+	err := common.AssertAdmin(ctx, "IssueStudentSecretReset")
+	if err != nil {
+		return err
+	}
+
+	dat, err := ctx.GetStudent(req.StudentID)
+	if err != nil {
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding student for - %s", req.StudentID)
+	}
+
+	issuedBy, err := common.GetClientID(ctx)
+	if err != nil {
+		return err
+	}
+	issuedOn, err := ctx.GetTxTime()
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
 	}
-	return shim.Success(queryResults)
+	expiresOn, err := common.AddMinutes(issuedOn, req.ExpiresInMinutes)
+	if err != nil {
+		return err
+	}
+
+	dat.SecretReset = &SecretReset{strings.ToLower(req.ResetTokenHash), issuedBy, issuedOn, expiresOn}
+	err = ctx.PutStudent(dat)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventSecretResetIssued, SecretResetIssuedEvent{"STUDENT", req.StudentID, issuedOn, expiresOn})
+
+	fmt.Println("- end issueStudentSecretReset")
+	return nil
 }
 
-// query callback representing the query of a chaincode
-func getStudentById(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
+// ResetStudentSecret sets a new secret with the one-time reset token issued by an admin
+func (t *StudentChaincode) ResetStudentSecret(ctx TransactionContextInterface, req StudentIDRequest) error {
+	fmt.Println("starting resetStudentSecret")
+
+	resetToken, err := common.GetTransientSecret(ctx, "ResetStudentSecret", "ResetToken", 16, 128)
+	if err != nil {
+		return err
+	}
+	newSecret, err := common.GetTransientSecret(ctx, "ResetStudentSecret", "NewStudentSecret", 8, 72)
+	if err != nil {
+		return err
+	}
 
-	if len(args) != 1 {
-		return shim.Error("Incorrect number of arguments. Expecting name of the person to query")
+	dat, err := ctx.GetStudent(req.StudentID)
+	if err != nil {
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding student for - %s", req.StudentID)
+	}
+	if dat.SecretReset == nil {
+		return common.NewError(common.ErrInvalidState, "no secret reset was issued for student %s", req.StudentID)
 	}
 
-	studentID := args[0]
+	tokenHash := sha256.Sum256([]byte(resetToken))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(tokenHash[:])), []byte(dat.SecretReset.TokenHash)) != 1 {
+		return common.NewError(common.ErrUnauthorized, "not authorized to reset the secret of student %s", req.StudentID)
+	}
 
-	// Get the state from the ledger
-	studentbytes, err := stub.GetState(studentID)
+	resetOn, err := ctx.GetTxTime()
 	if err != nil {
-		jsonResp := "{\"Error\":\"Failed to get state for " + studentID + "\"}"
-		return shim.Error(jsonResp)
+		return err
+	}
+	if resetOn > dat.SecretReset.ExpiresOn {
+		return common.NewError(common.ErrInvalidState, "the secret reset of student %s expired on %s", req.StudentID, dat.SecretReset.ExpiresOn)
 	}
 
-	if studentbytes == nil {
-		jsonResp := "{\"Error\":\"Nil data for " + studentID + "\"}"
-		return shim.Error(jsonResp)
+	// the token is consumed with the reset, which also lifts a lock
+	dat.SecretReset = nil
+	dat.AuthState.Reset()
+	err = changeStudentSecret(ctx, dat, newSecret, SecretRotationReasonReset)
+	if err != nil {
+		return err
 	}
 
-	jsonResp := "{\"StudentID\":\"" + studentID + "\",\"data\":\"" + string(studentbytes) + "\"}"
-	fmt.Printf("Query Response:%s\n", jsonResp)
-	return shim.Success(studentbytes)
+	fmt.Println("- end resetStudentSecret")
+	return nil
 }
 
-func updateAnsweredQuestions(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	var err error
-	fmt.Println("starting updateStudentAnsweredQuestions")
+// AddStudentTech adds another tech to the student, starting with the same reputation as the initial tech
+func (t *StudentChaincode) AddStudentTech(ctx TransactionContextInterface, req StudentTechAuthRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting addStudentTech")
 
-	if len(args) != 2 {
-		return shim.Error("Incorrect number of arguments. Expecting 2")
+	dat, result, err := getAuthenticatedStudent(ctx, "AddStudentTech", req.StudentID)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	techName, index, err := findStudentTechByName(ctx, req.TechsChaincode, dat, req.TechName)
+	if err != nil {
+		return nil, err
+	}
+	if index >= 0 {
+		return nil, common.NewError(common.ErrAlreadyExists, "student %s already has the tech %s", req.StudentID, techName)
 	}
 
-	//input sanitation
-	err = sanitize_arguments(args)
+	addedOn, err := ctx.GetTxTime()
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, err
 	}
+	techRepu := CreateStudentTechRepuObject(techName, addedOn)
+	dat.StudentTechRepus = append(dat.StudentTechRepus, techRepu)
 
-	studentID := args[0]
-	answeredQuestionID := args[1]
+	err = ctx.PutStudent(dat)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitEvent(EventTechAdded, TechAddedEvent{"STUDENT", req.StudentID, techRepu.UniqueTechName, techRepu.AttainedRepu, addedOn})
+
+	fmt.Println("- end addStudentTech")
+	return result, nil
+}
 
-	studentAsBytes, err := stub.GetState(studentID)
+// RemoveTech removes a tech and its reputation from the student, the last tech cannot be removed
+func (t *StudentChaincode) RemoveTech(ctx TransactionContextInterface, req StudentTechAuthRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting removeTech")
 
-	if err != nil { //this seems to always succeed, even if key didn't exist
-		fmt.Println("Error in finding Student - " + studentID)
-		return shim.Error("error in finding student for - " + studentID)
+	dat, result, err := getAuthenticatedStudent(ctx, "RemoveTech", req.StudentID)
+	if err != nil || !result.Authenticated {
+		return result, err
+	}
+	_, index, err := findStudentTechByName(ctx, req.TechsChaincode, dat, req.TechName)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		return nil, common.NewError(common.ErrNotFound, "tech repu not found for student %s", req.StudentID)
+	}
+	if len(dat.StudentTechRepus) == 1 {
+		return nil, common.NewError(common.ErrInvalidState, "cannot remove the only tech of student %s", req.StudentID)
 	}
 
-	str := fmt.Sprintf("%s", studentAsBytes)
-	fmt.Println("string is " + str)
+	removedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	removed := dat.StudentTechRepus[index]
+	dat.StudentTechRepus = append(dat.StudentTechRepus[:index], dat.StudentTechRepus[index+1:]...)
 
-	dat, err := JSONtoStu(studentAsBytes)
+	err = ctx.PutStudent(dat)
 	if err != nil {
-		return shim.Error("unable to convert jsonToDoc for" + studentID)
+		return nil, err
 	}
-	stuAnsweredQuestions := dat.AnsweredQuestions
-	if contains(stuAnsweredQuestions, answeredQuestionID) {
-		errorStr := "already answered cant repeat "
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+	ctx.EmitEvent(EventTechRemoved, TechRemovedEvent{"STUDENT", req.StudentID, removed.UniqueTechName, removed.AttainedRepu, removedOn})
+
+	fmt.Println("- end removeTech")
+	return result, nil
+}
+
+// AuthenticateStudent audit transaction checking the secret of the student, submit it as a transaction so a failure is
+// committed and counts towards the lockout, a success clears the failures
+func (t *StudentChaincode) AuthenticateStudent(ctx TransactionContextInterface, req StudentIDRequest) (*common.AuthenticationResult, error) {
+	fmt.Println("starting authenticateStudent")
+
+	secret, err := common.GetTransientSecret(ctx, "AuthenticateStudent", "StudentSecret", 0, 72)
+	if err != nil {
+		return nil, err
+	}
+
+	dat, err := ctx.GetStudent(req.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if dat == nil {
+		return nil, common.NewError(common.ErrNotFound, "error in finding student for - %s", req.StudentID)
+	}
+	result, err := checkStudentSecret(ctx, dat, secret)
+	if err != nil {
+		return nil, err
 	}
-	stuAnsweredQuestions = append(stuAnsweredQuestions, answeredQuestionID)
 
-	updatedStudent := Student{dat.StudentID, dat.StudentSecret, dat.StudentTechRepus, stuAnsweredQuestions, dat.CreatedON}
+	fmt.Println("- end authenticateStudent")
+	return result, nil
+}
 
-	buff, err := StuToJSON(updatedStudent)
+// UnlockStudent admin only, forgets the failed authentications of the student and lifts its lock
+func (t *StudentChaincode) UnlockStudent(ctx TransactionContextInterface, req StudentIDRequest) error {
+	err := common.AssertAdmin(ctx, "UnlockStudent")
 	if err != nil {
-		errorStr := "updateDispatchOrder() : Failed Cannot create object buffer for write : " + args[1]
-		fmt.Println(errorStr)
-		return shim.Error(errorStr)
+		return err
 	}
 
-	err = stub.PutState(studentID, buff) //store marble with id as key
+	dat, err := ctx.GetStudent(req.StudentID)
+	if err != nil {
+		return err
+	}
+	if dat == nil {
+		return common.NewError(common.ErrNotFound, "error in finding student for - %s", req.StudentID)
+	}
+	unlockedBy, err := common.GetClientID(ctx)
 	if err != nil {
-		return shim.Error(err.Error())
+		return err
+	}
+	unlockedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("- end updateStudentAnsweredQuestions")
-	return shim.Success(nil)
+	dat.AuthState.Reset()
+	err = ctx.PutStudent(dat)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(common.EventAccountUnlocked, common.AccountUnlockedEvent{SubjectType: "STUDENT", SubjectID: req.StudentID, UnlockedBy: unlockedBy, UnlockedOn: unlockedOn})
+	return nil
+}
+
+// SetConfig admin only, replaces the lockout settings
+func (t *StudentChaincode) SetConfig(ctx TransactionContextInterface, config common.AccountConfig) error {
+	return common.SetAccountConfig(ctx, config)
+}
+
+// GetConfig returns the settings in use, the defaults until an admin set them
+func (t *StudentChaincode) GetConfig(ctx TransactionContextInterface) (*common.AccountConfig, error) {
+	return common.GetAccountConfig(ctx)
+}
+
+// GetStudentHistory every version of the student in its key history, secrets and reset tokens are left out
+func (t *StudentChaincode) GetStudentHistory(ctx TransactionContextInterface, req StudentIDRequest) ([]AuditHistory, error) {
+	return getHistory(ctx, req.StudentID)
+}
+
+// GetRequestSchemas lists the declared request schema of every function
+func (t *StudentChaincode) GetRequestSchemas(ctx TransactionContextInterface) []common.RequestSchema {
+	return common.RequestSchemas(t)
 }
 
 // ============================================== Private Library ===========================================================
 
-func getQueryResultForQueryString(stub shim.ChaincodeStubInterface, queryString string) ([]byte, error) {
+func getQueryResultForQueryString(ctx TransactionContextInterface, queryString string) ([]*StudentQueryResult, error) {
 
 	fmt.Printf("- getQueryResultForQueryString queryString:\n%s\n", queryString)
 
-	resultsIterator, err := stub.GetQueryResult(queryString)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
 	if err != nil {
-		return nil, err
+		return nil, common.InternalError(err, "rich query failed")
 	}
 	defer resultsIterator.Close()
 
-	// buffer is a JSON array containing QueryRecords
-	var buffer bytes.Buffer
-	buffer.WriteString("[")
-
-	bArrayMemberAlreadyWritten := false
+	results := []*StudentQueryResult{}
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
 		if err != nil {
-			return nil, err
+			return nil, common.InternalError(err, "unable to iterate the query result")
 		}
-		// Add a comma before array members, suppress it for the first array member
-		if bArrayMemberAlreadyWritten == true {
-			buffer.WriteString(",")
+
+		stu, err := JSONtoStu(queryResponse.Value)
+		if err != nil {
+			return nil, common.InternalError(err, "unable to unmarshall student - %s", queryResponse.Key)
 		}
-		buffer.WriteString("{\"Key\":")
-		buffer.WriteString("\"")
-		buffer.WriteString(queryResponse.Key)
-		buffer.WriteString("\"")
+		results = append(results, &StudentQueryResult{Key: queryResponse.Key, Record: redact(&stu)})
+	}
+
+	fmt.Printf("- getQueryResultForQueryString found %d records\n", len(results))
+
+	return results, nil
+}
+
+// getAuthenticatedStudent reads the student and checks its secret for a function the student calls itself, a wrong
+// secret is a rejected result the function returns as it is, so the failure is committed with the transaction
+func getAuthenticatedStudent(ctx TransactionContextInterface, function string, studentID string) (*Student, *common.AuthenticationResult, error) {
+	secret, err := common.GetTransientSecret(ctx, function, "StudentSecret", 0, 72)
+	if err != nil {
+		return nil, nil, err
+	}
+	dat, err := ctx.GetStudent(studentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dat == nil {
+		return nil, nil, common.NewError(common.ErrNotFound, "error in finding student for - %s", studentID)
+	}
+	result, err := checkStudentSecret(ctx, dat, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dat, result, nil
+}
 
-		buffer.WriteString(", \"Record\":")
-		// Record is a JSON object, so we write as-is
-		buffer.WriteString(string(queryResponse.Value))
-		buffer.WriteString("}")
-		bArrayMemberAlreadyWritten = true
+// checkStudentSecret checks the secret of the student once it is sure the student is not locked, a success forgets
+// the failures and a wrong secret is counted, either is written to the world state with the transaction
+func checkStudentSecret(ctx TransactionContextInterface, dat *Student, secret string) (*common.AuthenticationResult, error) {
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	if dat.IsLocked(now) {
+		return nil, common.LockedError("student", dat.StudentID, dat.LockedUntil)
 	}
-	buffer.WriteString("]")
 
-	fmt.Printf("- getQueryResultForQueryString queryResult:\n%s\n", buffer.String())
+	if common.CheckSecret(secret, dat.StudentSecret) {
+		// only write when there are failures to forget
+		if dat.AuthState != (common.AuthState{}) {
+			dat.AuthState.Reset()
+			err = ctx.PutStudent(dat)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &common.AuthenticationResult{Authenticated: true}, nil
+	}
 
-	return buffer.Bytes(), nil
+	err = common.RecordFailedAuthentication(ctx, &dat.AuthState, "STUDENT", dat.StudentID, now)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.PutStudent(dat)
+	if err != nil {
+		return nil, err
+	}
+	return &common.AuthenticationResult{Authenticated: false, FailedAuthAttempts: dat.FailedAuthAttempts, LockedUntil: dat.LockedUntil}, nil
 }
 
-// ========================================================
-// Input Sanitation - dumb input checking, look for empty strings
-// ========================================================
-func sanitize_arguments(strs []string) error {
-	for i, val := range strs {
-		if len(val) <= 0 {
-			return errors.New("Argument " + strconv.Itoa(i) + " must be a non-empty string")
+// findStudentTech index of the tech in the tech repus of the student, -1 if the student does not have it
+func findStudentTech(dat *Student, techName string) int {
+	for i, techRepuData := range dat.StudentTechRepus {
+		if techRepuData.UniqueTechName == techName {
+			return i
 		}
-		// if len(val) > 32 {
-		// 	return errors.New("Argument " + strconv.Itoa(i) + " must be <= 32 characters")
-		// }
 	}
-	return nil
+	return -1
 }
 
-// CreateAssetObject creates an asset
-func CreateStudentTechRepuObject(techName string) (TechRepu, error) {
-	techRepu := TechRepu{techName, 10, time.Now().Format("20060102150405")}
-	return techRepu, nil
+// findStudentTechByName the canonical id of the tech name and its index in the tech repus of the student, -1 if the student does
+// not have it, a legacy tech stored under the exact name is found without asking the tech registry
+func findStudentTechByName(ctx TransactionContextInterface, techsChaincode string, dat *Student, techName string) (string, int, error) {
+	index := findStudentTech(dat, techName)
+	if index >= 0 {
+		return techName, index, nil
+	}
+	tech, err := common.ResolveTech(ctx, techsChaincode, techName)
+	if err != nil {
+		return "", -1, err
+	}
+	return tech.TechID, findStudentTech(dat, tech.TechID), nil
 }
 
-// CreateAssetObject creates an asset
-func CreateStudentObject(args []string, techRepu TechRepu) (Student, error) {
-	var myStudent Student
+// changeStudentSecret stores the new secret and records why and when it changed, every change is kept in the key history
+func changeStudentSecret(ctx TransactionContextInterface, dat *Student, newSecret string, reason string) error {
+	rotatedOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	dat.StudentSecret = common.HashSecret(ctx, newSecret)
+	dat.SecretRotatedOn = rotatedOn
+	dat.SecretRotationReason = reason
+	err = ctx.PutStudent(dat)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventSecretRotated, SecretRotatedEvent{"STUDENT", dat.StudentID, reason, rotatedOn})
+	return nil
+}
 
-	// Check there are 10 Arguments provided as per the the struct
-	if len(args) != 2 {
-		fmt.Println("CreateStudentObject(): Incorrect number of arguments. Expecting 2 ")
-		return myStudent, errors.New("CreateStudentObject(): Incorrect number of arguments. Expecting 2 ")
+// redact the student as every read hands it out, never with the hash of its secret or of its pending reset token
+func redact(student *Student) *Student {
+	student.StudentSecret = ""
+	if student.SecretReset != nil {
+		secretReset := *student.SecretReset
+		secretReset.TokenHash = ""
+		student.SecretReset = &secretReset
 	}
-	dummyTechRepuArray := []TechRepu{}
-	dummyTechRepuArray = append(dummyTechRepuArray, techRepu)
+	return student
+}
 
-	strArr := []string{}
+// ============================================================================================================================
+// Get history of asset
+// ============================================================================================================================
+func getHistory(ctx TransactionContextInterface, studentID string) ([]AuditHistory, error) {
+	history := []AuditHistory{}
 
-	rawEvalSecret := args[1]
+	fmt.Printf("- start getHistoryForStudent: %s\n", studentID)
 
-	hashedpassword, err := HashPassword(rawEvalSecret)
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(studentID)
 	if err != nil {
+		return nil, common.InternalError(err, "unable to read the history of %s", studentID)
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		historyData, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the history of %s", studentID)
+		}
 
-		return myStudent, errors.New("error in hashing the password")
+		var tx AuditHistory
+		tx.TxId = historyData.TxId
+		if historyData.Value != nil {
+			var student Student
+			json.Unmarshal(historyData.Value, &student)
+			tx.Value = *redact(&student)
+		}
+		history = append(history, tx)
 	}
-	myStudent = Student{args[0], hashedpassword, dummyTechRepuArray, strArr, time.Now().Format("20060102150405")}
-	return myStudent, nil
+	fmt.Printf("- getHistoryForStudent returning %d entries\n", len(history))
+
+	return history, nil
+}
+
+// CreateStudentTechRepuObject creates a tech reputation entry
+func CreateStudentTechRepuObject(techName string, createdOn string) TechRepu {
+	return TechRepu{UniqueTechName: techName, AttainedRepu: 10, CreatedON: createdOn}
+}
+
+// CreateStudentObject creates a student asset with the hash of its secret, see common.HashSecret
+func CreateStudentObject(studentID string, hashedSecret string, techRepu TechRepu, createdOn string, enrollmentID string) Student {
+	dummyTechRepuArray := []TechRepu{}
+	dummyTechRepuArray = append(dummyTechRepuArray, techRepu)
+
+	strArr := []string{}
+	myStudent := Student{
+		StudentID:         studentID,
+		StudentSecret:     hashedSecret,
+		StudentTechRepus:  dummyTechRepuArray,
+		AnsweredQuestions: strArr,
+		CreatedON:         createdOn,
+		EnrollmentID:      enrollmentID,
+	}
+	return myStudent
 }
 
 func StuToJSON(stu Student) ([]byte, error) {
@@ -442,16 +929,6 @@ func stringInSlice(a string, list []string) bool {
 	return false
 }
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
-}
-
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
-
 func contains(techRepuArray []string, match string) bool {
 	flag := false
 	for _, data := range techRepuArray {
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Common"
+)
+
+func TestNewStudentChaincode(t *testing.T) {
+	_, err := newStudentChaincode()
+	if err != nil {
+		t.Errorf("newStudentChaincode failed - %s", err)
+	}
+	// panics on a constraint that is not a number
+	common.RequestSchemas(new(StudentChaincode))
+}
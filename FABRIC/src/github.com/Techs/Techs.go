@@ -0,0 +1,567 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TechChaincode contract for the registry of canonical techs, their aliases and their parent/child relations
+type TechChaincode struct {
+	contractapi.Contract
+}
+
+// ============================================================================================================================
+// Asset Definitions - The ledger will store techs keyed by their canonical tech id
+// ============================================================================================================================
+
+type Tech struct {
+	TechID    string   `json:"TechID"`
+	Name      string   `json:"Name"`
+	Aliases   []string `json:"Aliases"`
+	ParentID  string   `json:"ParentID"`
+	CreatedOn string   `json:"CreatedOn"`
+}
+
+// TechResolution the canonical tech a tech name resolves to, with its ancestors from the parent up to the root
+type TechResolution struct {
+	TechID    string   `json:"TechID"`
+	Name      string   `json:"Name"`
+	ParentID  string   `json:"ParentID"`
+	Ancestors []string `json:"Ancestors"`
+}
+
+// TechQueryResult structure used for handling result of rich queries
+type TechQueryResult struct {
+	Key    string `json:"Key"`
+	Record *Tech  `json:"Record"`
+}
+
+// aliases are indexed under a composite key pointing to the tech id, so a name resolves with a single read
+const aliasObjectType = "alias"
+
+// MaxTechDepth the deepest a tech can sit in the hierarchy, bounds every walk up the parents
+const MaxTechDepth = 16
+
+// ============================================================================================================================
+// Event Definitions - the events raised by the tech functions and their payloads, see Common/Events.go
+// ============================================================================================================================
+
+// names of the events raised by the tech functions
+const (
+	EventTechRegistered    = "TechRegistered"
+	EventTechAliasAdded    = "TechAliasAdded"
+	EventTechAliasRemoved  = "TechAliasRemoved"
+	EventTechParentChanged = "TechParentChanged"
+)
+
+// TechRegisteredEvent payload of TechRegistered, raised by AddTech
+type TechRegisteredEvent struct {
+	TechID    string   `json:"TechID"`
+	Name      string   `json:"Name"`
+	Aliases   []string `json:"Aliases"`
+	ParentID  string   `json:"ParentID"`
+	CreatedOn string   `json:"CreatedOn"`
+}
+
+// TechAliasEvent payload of TechAliasAdded and TechAliasRemoved
+type TechAliasEvent struct {
+	TechID string `json:"TechID"`
+	Alias  string `json:"Alias"`
+}
+
+// TechParentChangedEvent payload of TechParentChanged, raised by SetTechParent, an empty ParentID makes the tech a root
+type TechParentChangedEvent struct {
+	TechID           string `json:"TechID"`
+	PreviousParentID string `json:"PreviousParentID"`
+	ParentID         string `json:"ParentID"`
+}
+
+// ============================================================================================================================
+// Request Objects - the JSON request accepted by each function and its declared schema
+// ============================================================================================================================
+
+// InitLedgerRequest request object of InitLedger
+type InitLedgerRequest struct {
+	Key   string `json:"Key" validate:"maxlength=64"`
+	Value string `json:"Value" validate:"maxlength=256"`
+}
+
+// AddTechRequest request object of AddTech
+type AddTechRequest struct {
+	TechID   string   `json:"TechID" validate:"maxlength=64,format=techid"`
+	Name     string   `json:"Name" validate:"maxlength=64,format=tech"`
+	Aliases  []string `json:"Aliases" metadata:",optional" validate:"maxlength=64,format=tech"`
+	ParentID string   `json:"ParentID" metadata:",optional" validate:"maxlength=64,format=techid"`
+}
+
+// TechAliasRequest request object of AddTechAlias and RemoveTechAlias
+type TechAliasRequest struct {
+	TechID string `json:"TechID" validate:"maxlength=64,format=techid"`
+	Alias  string `json:"Alias" validate:"maxlength=64,format=tech"`
+}
+
+// SetTechParentRequest request object of SetTechParent
+type SetTechParentRequest struct {
+	TechID   string `json:"TechID" validate:"maxlength=64,format=techid"`
+	ParentID string `json:"ParentID" metadata:",optional" validate:"maxlength=64,format=techid"`
+}
+
+// TechIDRequest request object of the functions reading a single tech
+type TechIDRequest struct {
+	TechID string `json:"TechID" validate:"maxlength=64,format=techid"`
+}
+
+// ResolveTechRequest request object of ResolveTech
+type ResolveTechRequest struct {
+	TechName string `json:"TechName" validate:"maxlength=64,format=tech"`
+}
+
+// ============================================================================================================================
+// Transaction Context - helpers available to every tech transaction
+// ============================================================================================================================
+
+// TransactionContextInterface is the context handed to every TechChaincode function
+type TransactionContextInterface interface {
+	common.TransactionContextInterface
+	GetTech(techID string) (*Tech, error)
+	PutTech(tech *Tech) error
+	GetAliasTechID(alias string) (string, error)
+	PutAlias(alias string, techID string) error
+	DelAlias(alias string) error
+}
+
+// TransactionContext implementation of TransactionContextInterface
+type TransactionContext struct {
+	common.TransactionContext
+}
+
+// GetTech reads a tech from the world state, nil if it does not exist
+func (ctx *TransactionContext) GetTech(techID string) (*Tech, error) {
+	techAsBytes, err := ctx.GetStub().GetState(techID)
+	if err != nil {
+		return nil, common.InternalError(err, "error in finding tech for - %s", techID)
+	}
+	if techAsBytes == nil {
+		return nil, nil
+	}
+
+	tech := Tech{}
+	err = json.Unmarshal(techAsBytes, &tech)
+	if err != nil {
+		return nil, common.InternalError(err, "unable to unmarshall tech - %s", techID)
+	}
+	return &tech, nil
+}
+
+// PutTech writes a tech to the world state keyed by its tech id
+func (ctx *TransactionContext) PutTech(tech *Tech) error {
+	buff, err := json.Marshal(tech)
+	if err != nil {
+		return common.InternalError(err, "unable to convert tech to json")
+	}
+	err = ctx.GetStub().PutState(tech.TechID, buff)
+	if err != nil {
+		return common.InternalError(err, "unable to write tech - %s", tech.TechID)
+	}
+	return nil
+}
+
+// GetAliasTechID the tech id an alias points to, empty if the alias is not registered
+func (ctx *TransactionContext) GetAliasTechID(alias string) (string, error) {
+	aliasKey, err := ctx.GetStub().CreateCompositeKey(aliasObjectType, []string{alias})
+	if err != nil {
+		return "", common.InternalError(err, "unable to create the alias key for %s", alias)
+	}
+	techID, err := ctx.GetStub().GetState(aliasKey)
+	if err != nil {
+		return "", common.InternalError(err, "error in finding alias - %s", alias)
+	}
+	return string(techID), nil
+}
+
+// PutAlias points an alias to a tech id
+func (ctx *TransactionContext) PutAlias(alias string, techID string) error {
+	aliasKey, err := ctx.GetStub().CreateCompositeKey(aliasObjectType, []string{alias})
+	if err != nil {
+		return common.InternalError(err, "unable to create the alias key for %s", alias)
+	}
+	err = ctx.GetStub().PutState(aliasKey, []byte(techID))
+	if err != nil {
+		return common.InternalError(err, "unable to write alias - %s", alias)
+	}
+	return nil
+}
+
+// DelAlias removes an alias
+func (ctx *TransactionContext) DelAlias(alias string) error {
+	aliasKey, err := ctx.GetStub().CreateCompositeKey(aliasObjectType, []string{alias})
+	if err != nil {
+		return common.InternalError(err, "unable to create the alias key for %s", alias)
+	}
+	err = ctx.GetStub().DelState(aliasKey)
+	if err != nil {
+		return common.InternalError(err, "unable to delete alias - %s", alias)
+	}
+	return nil
+}
+
+// ============================================================================================================================
+// Main
+// ============================================================================================================================
+func main() {
+	chaincode, err := newTechChaincode()
+	if err != nil {
+		fmt.Printf("Error creating Tech chaincode - %s", err)
+		return
+	}
+
+	err = chaincode.Start()
+	if err != nil {
+		fmt.Printf("Error starting Tech chaincode - %s", err)
+	}
+}
+
+// newTechChaincode the contract api chaincode of the Tech contract, fails when the contract's metadata is rejected
+func newTechChaincode() (*contractapi.ContractChaincode, error) {
+	techChaincode := new(TechChaincode)
+	techChaincode.TransactionContextHandler = new(TransactionContext)
+	techChaincode.UnknownTransaction = common.UnknownTransaction
+	techChaincode.BeforeTransaction = common.BeforeTransaction(techChaincode)
+	techChaincode.AfterTransaction = common.AfterTransaction
+
+	chaincode, err := contractapi.NewChaincode(techChaincode)
+	if err != nil {
+		return nil, err
+	}
+	chaincode.Info.Title = "Techs"
+	chaincode.Info.Version = "2.0.0"
+	return chaincode, nil
+}
+
+// ============================================================================================================================
+// InitLedger - initialize the chaincode
+// ============================================================================================================================
+func (t *TechChaincode) InitLedger(ctx TransactionContextInterface, req InitLedgerRequest) error {
+	fmt.Println("Tech Registry Is Starting Up")
+	fmt.Println("  InitLedger() is running")
+	fmt.Println("  Transaction ID: ", ctx.GetStub().GetTxID())
+
+	err := ctx.GetStub().PutState(req.Key, []byte(req.Value))
+	if err != nil {
+		return common.InternalError(err, "self-test write failed") //self-test fail
+	}
+
+	fmt.Println("Ready for action") //self-test pass
+	return nil
+}
+
+// AddTech admin only, registers a canonical tech with its aliases, optionally under a parent tech
+func (t *TechChaincode) AddTech(ctx TransactionContextInterface, req AddTechRequest) error {
+	fmt.Println("starting addTech")
+
+	err := common.AssertAdmin(ctx, "AddTech")
+	if err != nil {
+		return err
+	}
+
+	err = assertNameFree(ctx, req.TechID)
+	if err != nil {
+		return err
+	}
+	if req.ParentID != "" {
+		parent, err := ctx.GetTech(req.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return common.NewError(common.ErrNotFound, "parent tech not found - %s", req.ParentID)
+		}
+		ancestors, err := getAncestors(ctx, parent)
+		if err != nil {
+			return err
+		}
+		if len(ancestors)+2 > MaxTechDepth {
+			return common.NewError(common.ErrInvalidState, "tech %s would be deeper than %d levels", req.TechID, MaxTechDepth)
+		}
+	}
+
+	createdOn, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	tech := Tech{req.TechID, req.Name, []string{}, req.ParentID, createdOn}
+
+	// the display name resolves as well
+	for _, alias := range append([]string{req.Name}, req.Aliases...) {
+		alias = normalizeTechName(alias)
+		if alias == tech.TechID || stringInSlice(alias, tech.Aliases) {
+			continue
+		}
+		err = assertNameFree(ctx, alias)
+		if err != nil {
+			return err
+		}
+		err = ctx.PutAlias(alias, tech.TechID)
+		if err != nil {
+			return err
+		}
+		tech.Aliases = append(tech.Aliases, alias)
+	}
+
+	err = ctx.PutTech(&tech)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventTechRegistered, TechRegisteredEvent{tech.TechID, tech.Name, tech.Aliases, tech.ParentID, createdOn})
+
+	fmt.Println("- end addTech")
+	return nil
+}
+
+// AddTechAlias admin only, lets another name resolve to the tech
+func (t *TechChaincode) AddTechAlias(ctx TransactionContextInterface, req TechAliasRequest) error {
+	err := common.AssertAdmin(ctx, "AddTechAlias")
+	if err != nil {
+		return err
+	}
+
+	tech, err := getTechById(ctx, req.TechID)
+	if err != nil {
+		return err
+	}
+	alias := normalizeTechName(req.Alias)
+	err = assertNameFree(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.PutAlias(alias, tech.TechID)
+	if err != nil {
+		return err
+	}
+	tech.Aliases = append(tech.Aliases, alias)
+	err = ctx.PutTech(tech)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventTechAliasAdded, TechAliasEvent{tech.TechID, alias})
+	return nil
+}
+
+// RemoveTechAlias admin only, the name no longer resolves to the tech
+func (t *TechChaincode) RemoveTechAlias(ctx TransactionContextInterface, req TechAliasRequest) error {
+	err := common.AssertAdmin(ctx, "RemoveTechAlias")
+	if err != nil {
+		return err
+	}
+
+	tech, err := getTechById(ctx, req.TechID)
+	if err != nil {
+		return err
+	}
+	alias := normalizeTechName(req.Alias)
+	index := -1
+	for i, techAlias := range tech.Aliases {
+		if techAlias == alias {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return common.NewError(common.ErrNotFound, "%s is not an alias of tech %s", alias, tech.TechID)
+	}
+
+	err = ctx.DelAlias(alias)
+	if err != nil {
+		return err
+	}
+	tech.Aliases = append(tech.Aliases[:index], tech.Aliases[index+1:]...)
+	err = ctx.PutTech(tech)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventTechAliasRemoved, TechAliasEvent{tech.TechID, alias})
+	return nil
+}
+
+// SetTechParent admin only, moves the tech under another parent, an empty ParentID makes it a root tech
+func (t *TechChaincode) SetTechParent(ctx TransactionContextInterface, req SetTechParentRequest) error {
+	err := common.AssertAdmin(ctx, "SetTechParent")
+	if err != nil {
+		return err
+	}
+
+	tech, err := getTechById(ctx, req.TechID)
+	if err != nil {
+		return err
+	}
+	if req.ParentID != "" {
+		parent, err := getTechById(ctx, req.ParentID)
+		if err != nil {
+			return err
+		}
+		ancestors, err := getAncestors(ctx, parent)
+		if err != nil {
+			return err
+		}
+		if parent.TechID == tech.TechID || stringInSlice(tech.TechID, ancestors) {
+			return common.NewError(common.ErrInvalidState, "tech %s cannot be its own ancestor", tech.TechID)
+		}
+		if len(ancestors)+2 > MaxTechDepth {
+			return common.NewError(common.ErrInvalidState, "tech %s would be deeper than %d levels", tech.TechID, MaxTechDepth)
+		}
+	}
+
+	previousParentID := tech.ParentID
+	tech.ParentID = req.ParentID
+	err = ctx.PutTech(tech)
+	if err != nil {
+		return err
+	}
+	ctx.EmitEvent(EventTechParentChanged, TechParentChangedEvent{tech.TechID, previousParentID, tech.ParentID})
+	return nil
+}
+
+// GetTechById returns the tech stored against the canonical tech id
+func (t *TechChaincode) GetTechById(ctx TransactionContextInterface, req TechIDRequest) (*Tech, error) {
+	return getTechById(ctx, req.TechID)
+}
+
+// QueryChildTechs rich query for the techs directly under the tech
+func (t *TechChaincode) QueryChildTechs(ctx TransactionContextInterface, req TechIDRequest) ([]*TechQueryResult, error) {
+	queryString := fmt.Sprintf("{\"selector\":{\"ParentID\":\"%s\"}}", req.TechID)
+
+	return getQueryResultForQueryString(ctx, queryString)
+}
+
+// ResolveTech resolves a tech name, the canonical id or any alias in any case, to its canonical tech,
+// required by the other chaincodes to normalise the tech names they are given
+func (t *TechChaincode) ResolveTech(ctx TransactionContextInterface, req ResolveTechRequest) (*TechResolution, error) {
+	name := normalizeTechName(req.TechName)
+	tech, err := ctx.GetTech(name)
+	if err != nil {
+		return nil, err
+	}
+	if tech == nil {
+		techID, err := ctx.GetAliasTechID(name)
+		if err != nil {
+			return nil, err
+		}
+		if techID == "" {
+			return nil, common.NewError(common.ErrNotFound, "unknown tech %s", req.TechName)
+		}
+		tech, err = getTechById(ctx, techID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ancestors, err := getAncestors(ctx, tech)
+	if err != nil {
+		return nil, err
+	}
+	return &TechResolution{tech.TechID, tech.Name, tech.ParentID, ancestors}, nil
+}
+
+// GetRequestSchemas lists the declared request schema of every function
+func (t *TechChaincode) GetRequestSchemas(ctx TransactionContextInterface) []common.RequestSchema {
+	return common.RequestSchemas(t)
+}
+
+// =========================================== Private Libraries ========================================================
+
+func getTechById(ctx TransactionContextInterface, techID string) (*Tech, error) {
+	tech, err := ctx.GetTech(techID)
+	if err != nil {
+		return nil, err
+	}
+	if tech == nil {
+		return nil, common.NewError(common.ErrNotFound, "Nil data for %s", techID)
+	}
+	return tech, nil
+}
+
+// getAncestors the tech ids from the parent of the tech up to its root
+func getAncestors(ctx TransactionContextInterface, tech *Tech) ([]string, error) {
+	ancestors := []string{}
+	parentID := tech.ParentID
+	for parentID != "" {
+		if len(ancestors) >= MaxTechDepth {
+			return nil, common.NewError(common.ErrInvalidState, "the ancestors of tech %s are deeper than %d levels", tech.TechID, MaxTechDepth)
+		}
+		parent, err := getTechById(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, parent.TechID)
+		parentID = parent.ParentID
+	}
+	return ancestors, nil
+}
+
+// assertNameFree fails with ALREADY_EXISTS when the name is already a tech id or an alias
+func assertNameFree(ctx TransactionContextInterface, name string) error {
+	tech, err := ctx.GetTech(name)
+	if err != nil {
+		return err
+	}
+	if tech != nil {
+		return common.NewError(common.ErrAlreadyExists, "%s is already a tech", name)
+	}
+	techID, err := ctx.GetAliasTechID(name)
+	if err != nil {
+		return err
+	}
+	if techID != "" {
+		return common.NewError(common.ErrAlreadyExists, "%s is already an alias of tech %s", name, techID)
+	}
+	return nil
+}
+
+// normalizeTechName lower cases the name and collapses its white space, "Go  Lang" and "go lang" are the same name
+func normalizeTechName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func getQueryResultForQueryString(ctx TransactionContextInterface, queryString string) ([]*TechQueryResult, error) {
+
+	fmt.Printf("- getQueryResultForQueryString queryString:\n%s\n", queryString)
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, common.InternalError(err, "rich query failed")
+	}
+	defer resultsIterator.Close()
+
+	results := []*TechQueryResult{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, common.InternalError(err, "unable to iterate the query result")
+		}
+
+		tech := Tech{}
+		err = json.Unmarshal(queryResponse.Value, &tech)
+		if err != nil {
+			return nil, common.InternalError(err, "unable to unmarshall tech - %s", queryResponse.Key)
+		}
+		results = append(results, &TechQueryResult{Key: queryResponse.Key, Record: &tech})
+	}
+
+	fmt.Printf("- getQueryResultForQueryString found %d records\n", len(results))
+
+	return results, nil
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
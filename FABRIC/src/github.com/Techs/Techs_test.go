@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Common"
+)
+
+func TestNewTechChaincode(t *testing.T) {
+	_, err := newTechChaincode()
+	if err != nil {
+		t.Errorf("newTechChaincode failed - %s", err)
+	}
+	// panics on a constraint that is not a number
+	common.RequestSchemas(new(TechChaincode))
+}